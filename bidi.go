@@ -0,0 +1,117 @@
+package navigator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ikawaha/navigator/webdriver/bidi"
+)
+
+// BiDi returns the WebDriver BiDi client of the page. The connection is opened
+// on the first call. The page must be created with the BiDi Option.
+func (p *Page) BiDi() (*bidi.Client, error) {
+	return p.BiDiWithContext(context.Background())
+}
+
+// BiDiWithContext returns the WebDriver BiDi client of the page. The connection
+// is opened on the first call. The page must be created with the BiDi Option.
+func (p *Page) BiDiWithContext(ctx context.Context) (*bidi.Client, error) {
+	p.mu.Lock()
+	c := p.bidi
+	p.mu.Unlock()
+	if c != nil {
+		return c, nil
+	}
+	url := p.session.WebSocketURL()
+	if url == "" {
+		return nil, errors.New("failed to connect to WebDriver BiDi: webSocketUrl was not granted, use the BiDi Option")
+	}
+	// dial without the lock, which would block the other methods of the page.
+	c, err := bidi.Dial(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to WebDriver BiDi: %w", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.bidi != nil {
+		// the connection was opened by a concurrent call.
+		_ = c.Close()
+		return p.bidi, nil
+	}
+	p.bidi = c
+	return c, nil
+}
+
+func (p *Page) subscribe(ctx context.Context, event string, register func(c *bidi.Client) (remove func())) error {
+	c, err := p.BiDiWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	remove := register(c)
+	if err := c.Subscribe(ctx, event); err != nil {
+		remove()
+		return fmt.Errorf("failed to subscribe to %s: %w", event, err)
+	}
+	return nil
+}
+
+// OnLogEntry registers the handler called on each console or JavaScript log entry.
+func (p *Page) OnLogEntry(handler func(bidi.LogEntry)) error {
+	return p.OnLogEntryWithContext(context.Background(), handler)
+}
+
+// OnLogEntryWithContext registers the handler called on each console or JavaScript log entry.
+func (p *Page) OnLogEntryWithContext(ctx context.Context, handler func(bidi.LogEntry)) error {
+	return p.subscribe(ctx, bidi.EventLogEntryAdded, func(c *bidi.Client) func() {
+		return c.OnLogEntryAdded(handler)
+	})
+}
+
+// OnLoad registers the handler called when a page of a browsing context is loaded.
+func (p *Page) OnLoad(handler func(bidi.NavigationInfo)) error {
+	return p.OnLoadWithContext(context.Background(), handler)
+}
+
+// OnLoadWithContext registers the handler called when a page of a browsing context is loaded.
+func (p *Page) OnLoadWithContext(ctx context.Context, handler func(bidi.NavigationInfo)) error {
+	return p.subscribe(ctx, bidi.EventBrowsingContextLoad, func(c *bidi.Client) func() {
+		return c.OnLoad(handler)
+	})
+}
+
+// OnNavigationStarted registers the handler called when a navigation is started.
+func (p *Page) OnNavigationStarted(handler func(bidi.NavigationInfo)) error {
+	return p.OnNavigationStartedWithContext(context.Background(), handler)
+}
+
+// OnNavigationStartedWithContext registers the handler called when a navigation is started.
+func (p *Page) OnNavigationStartedWithContext(ctx context.Context, handler func(bidi.NavigationInfo)) error {
+	return p.subscribe(ctx, bidi.EventBrowsingContextNavigationStarted, func(c *bidi.Client) func() {
+		return c.OnNavigationStarted(handler)
+	})
+}
+
+// OnBeforeRequestSent registers the handler called before a request is sent.
+func (p *Page) OnBeforeRequestSent(handler func(bidi.BeforeRequestSent)) error {
+	return p.OnBeforeRequestSentWithContext(context.Background(), handler)
+}
+
+// OnBeforeRequestSentWithContext registers the handler called before a request is sent.
+func (p *Page) OnBeforeRequestSentWithContext(ctx context.Context, handler func(bidi.BeforeRequestSent)) error {
+	return p.subscribe(ctx, bidi.EventNetworkBeforeRequestSent, func(c *bidi.Client) func() {
+		return c.OnBeforeRequestSent(handler)
+	})
+}
+
+// OnResponseCompleted registers the handler called when a response is completed.
+func (p *Page) OnResponseCompleted(handler func(bidi.ResponseCompleted)) error {
+	return p.OnResponseCompletedWithContext(context.Background(), handler)
+}
+
+// OnResponseCompletedWithContext registers the handler called when a response is completed.
+func (p *Page) OnResponseCompletedWithContext(ctx context.Context, handler func(bidi.ResponseCompleted)) error {
+	return p.subscribe(ctx, bidi.EventNetworkResponseCompleted, func(c *bidi.Client) func() {
+		return c.OnResponseCompleted(handler)
+	})
+}
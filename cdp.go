@@ -0,0 +1,28 @@
+package navigator
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExecuteCDP executes the Chrome DevTools Protocol command with the params,
+// and unmarshals the result of the command into the result. It fails on
+// browsers other than Chromium based ones. Typed helpers for common domains
+// are provided by the cdp package.
+//
+//	var version struct{ Product string }
+//	page.ExecuteCDP("Browser.getVersion", nil, &version)
+func (p *Page) ExecuteCDP(method string, params, result any) error {
+	return p.ExecuteCDPWithContext(context.Background(), method, params, result)
+}
+
+// ExecuteCDPWithContext executes the Chrome DevTools Protocol command with
+// the params, and unmarshals the result of the command into the result.
+// It fails on browsers other than Chromium based ones. Typed helpers for
+// common domains are provided by the cdp package.
+func (p *Page) ExecuteCDPWithContext(ctx context.Context, method string, params, result any) error {
+	if err := p.session.ExecuteCDP(ctx, method, params, result); err != nil {
+		return fmt.Errorf("failed to execute %s: %w", method, err)
+	}
+	return nil
+}
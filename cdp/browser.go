@@ -0,0 +1,40 @@
+package cdp
+
+import (
+	"context"
+)
+
+// Version represents the version information of the browser.
+type Version struct {
+	ProtocolVersion string `json:"protocolVersion"`
+	Product         string `json:"product"`
+	Revision        string `json:"revision"`
+	UserAgent       string `json:"userAgent"`
+	JSVersion       string `json:"jsVersion"`
+}
+
+// GetVersion returns the version information of the browser.
+func GetVersion(ctx context.Context, e Executor) (*Version, error) {
+	var v Version
+	if err := e.ExecuteCDP(ctx, "Browser.getVersion", nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// GrantPermissions grants the permissions (ex. "geolocation") to the origin.
+// An empty origin grants the permissions to all origins.
+func GrantPermissions(ctx context.Context, e Executor, origin string, permissions ...string) error {
+	params := map[string]any{
+		"permissions": permissions,
+	}
+	if origin != "" {
+		params["origin"] = origin
+	}
+	return e.ExecuteCDP(ctx, "Browser.grantPermissions", params, nil)
+}
+
+// ResetPermissions resets all permission management of the browser.
+func ResetPermissions(ctx context.Context, e Executor) error {
+	return e.ExecuteCDP(ctx, "Browser.resetPermissions", nil, nil)
+}
@@ -0,0 +1,11 @@
+package cdp
+
+import (
+	"context"
+)
+
+// Executor executes Chrome DevTools Protocol commands.
+// *session.Session implements this interface.
+type Executor interface {
+	ExecuteCDP(ctx context.Context, method string, params, result any) error
+}
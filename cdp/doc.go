@@ -0,0 +1,8 @@
+// Package cdp provides typed helpers for common Chrome DevTools Protocol domains.
+//
+// The helpers send commands through an Executor, such as the session of a page:
+//
+//	cdp.ClearBrowserCookies(ctx, page.Session())
+//
+// See: https://chromedevtools.github.io/devtools-protocol/
+package cdp
@@ -0,0 +1,87 @@
+package cdp
+
+import (
+	"context"
+)
+
+// DeviceMetrics represents the overridden metrics of the device.
+type DeviceMetrics struct {
+	Width             int     `json:"width"`
+	Height            int     `json:"height"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor"`
+	Mobile            bool    `json:"mobile"`
+}
+
+// SetDeviceMetricsOverride overrides the metrics of the device.
+func SetDeviceMetricsOverride(ctx context.Context, e Executor, metrics DeviceMetrics) error {
+	return e.ExecuteCDP(ctx, "Emulation.setDeviceMetricsOverride", metrics, nil)
+}
+
+// ClearDeviceMetricsOverride clears the overridden metrics of the device.
+func ClearDeviceMetricsOverride(ctx context.Context, e Executor) error {
+	return e.ExecuteCDP(ctx, "Emulation.clearDeviceMetricsOverride", nil, nil)
+}
+
+// SetUserAgentOverride overrides the user agent of the browser.
+func SetUserAgentOverride(ctx context.Context, e Executor, userAgent string) error {
+	return e.ExecuteCDP(ctx, "Emulation.setUserAgentOverride", map[string]any{
+		"userAgent": userAgent,
+	}, nil)
+}
+
+// SetTouchEmulationEnabled enables or disables the emulation of touch events.
+func SetTouchEmulationEnabled(ctx context.Context, e Executor, enabled bool) error {
+	return e.ExecuteCDP(ctx, "Emulation.setTouchEmulationEnabled", map[string]any{
+		"enabled": enabled,
+	}, nil)
+}
+
+// SetGeolocationOverride overrides the geolocation position.
+func SetGeolocationOverride(ctx context.Context, e Executor, latitude, longitude, accuracy float64) error {
+	return e.ExecuteCDP(ctx, "Emulation.setGeolocationOverride", map[string]any{
+		"latitude":  latitude,
+		"longitude": longitude,
+		"accuracy":  accuracy,
+	}, nil)
+}
+
+// ClearGeolocationOverride clears the overridden geolocation position.
+func ClearGeolocationOverride(ctx context.Context, e Executor) error {
+	return e.ExecuteCDP(ctx, "Emulation.clearGeolocationOverride", nil, nil)
+}
+
+// SetTimezoneOverride overrides the timezone (ex. "Asia/Tokyo").
+// An empty timezone restores the default timezone.
+func SetTimezoneOverride(ctx context.Context, e Executor, timezoneID string) error {
+	return e.ExecuteCDP(ctx, "Emulation.setTimezoneOverride", map[string]any{
+		"timezoneId": timezoneID,
+	}, nil)
+}
+
+// SetLocaleOverride overrides the ICU locale (ex. "ja_JP").
+// An empty locale restores the default locale.
+func SetLocaleOverride(ctx context.Context, e Executor, locale string) error {
+	params := map[string]any{}
+	if locale != "" {
+		params["locale"] = locale
+	}
+	return e.ExecuteCDP(ctx, "Emulation.setLocaleOverride", params, nil)
+}
+
+// MediaFeature is a CSS media feature (ex. "prefers-color-scheme") and its value.
+type MediaFeature struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SetEmulatedMedia emulates the CSS media type (ex. "print") and the media
+// features. An empty media type and no features restore the defaults.
+func SetEmulatedMedia(ctx context.Context, e Executor, media string, features []MediaFeature) error {
+	if features == nil {
+		features = []MediaFeature{}
+	}
+	return e.ExecuteCDP(ctx, "Emulation.setEmulatedMedia", map[string]any{
+		"media":    media,
+		"features": features,
+	}, nil)
+}
@@ -0,0 +1,52 @@
+package cdp
+
+import (
+	"context"
+)
+
+// ClearBrowserCookies clears all cookies of the browser, including the cookies
+// of domains other than the current one.
+func ClearBrowserCookies(ctx context.Context, e Executor) error {
+	return e.ExecuteCDP(ctx, "Network.clearBrowserCookies", nil, nil)
+}
+
+// ClearBrowserCache clears the cache of the browser.
+func ClearBrowserCache(ctx context.Context, e Executor) error {
+	return e.ExecuteCDP(ctx, "Network.clearBrowserCache", nil, nil)
+}
+
+// SetCacheDisabled toggles ignoring the cache for each request.
+func SetCacheDisabled(ctx context.Context, e Executor, disabled bool) error {
+	return e.ExecuteCDP(ctx, "Network.setCacheDisabled", map[string]any{
+		"cacheDisabled": disabled,
+	}, nil)
+}
+
+// NetworkConditions represents the emulated network conditions.
+type NetworkConditions struct {
+	Offline bool `json:"offline"`
+	// Latency is the additional latency in milliseconds.
+	Latency float64 `json:"latency"`
+	// DownloadThroughput is the maximal throughput in bytes/sec (-1 disables throttling).
+	DownloadThroughput float64 `json:"downloadThroughput"`
+	// UploadThroughput is the maximal throughput in bytes/sec (-1 disables throttling).
+	UploadThroughput float64 `json:"uploadThroughput"`
+}
+
+// EmulateNetworkConditions activates the emulation of the network conditions.
+func EmulateNetworkConditions(ctx context.Context, e Executor, conditions NetworkConditions) error {
+	if err := e.ExecuteCDP(ctx, "Network.enable", nil, nil); err != nil {
+		return err
+	}
+	return e.ExecuteCDP(ctx, "Network.emulateNetworkConditions", conditions, nil)
+}
+
+// SetExtraHTTPHeaders sets the headers sent with every request of the page.
+func SetExtraHTTPHeaders(ctx context.Context, e Executor, headers map[string]string) error {
+	if err := e.ExecuteCDP(ctx, "Network.enable", nil, nil); err != nil {
+		return err
+	}
+	return e.ExecuteCDP(ctx, "Network.setExtraHTTPHeaders", map[string]any{
+		"headers": headers,
+	}, nil)
+}
@@ -0,0 +1,57 @@
+package cdp
+
+import (
+	"context"
+)
+
+// ScriptCoverage represents the coverage data of a script.
+type ScriptCoverage struct {
+	ScriptID  string             `json:"scriptId"`
+	URL       string             `json:"url"`
+	Functions []FunctionCoverage `json:"functions"`
+}
+
+// FunctionCoverage represents the coverage data of a function.
+type FunctionCoverage struct {
+	FunctionName    string          `json:"functionName"`
+	Ranges          []CoverageRange `json:"ranges"`
+	IsBlockCoverage bool            `json:"isBlockCoverage"`
+}
+
+// CoverageRange represents the coverage data of a source range.
+type CoverageRange struct {
+	StartOffset int `json:"startOffset"`
+	EndOffset   int `json:"endOffset"`
+	Count       int `json:"count"`
+}
+
+// StartPreciseCoverage enables the profiler and starts collecting the
+// JavaScript coverage.
+func StartPreciseCoverage(ctx context.Context, e Executor, callCount, detailed bool) error {
+	if err := e.ExecuteCDP(ctx, "Profiler.enable", nil, nil); err != nil {
+		return err
+	}
+	return e.ExecuteCDP(ctx, "Profiler.startPreciseCoverage", map[string]any{
+		"callCount": callCount,
+		"detailed":  detailed,
+	}, nil)
+}
+
+// TakePreciseCoverage returns the JavaScript coverage collected since the last call.
+func TakePreciseCoverage(ctx context.Context, e Executor) ([]ScriptCoverage, error) {
+	var result struct {
+		Result []ScriptCoverage `json:"result"`
+	}
+	if err := e.ExecuteCDP(ctx, "Profiler.takePreciseCoverage", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Result, nil
+}
+
+// StopPreciseCoverage stops collecting the JavaScript coverage and disables the profiler.
+func StopPreciseCoverage(ctx context.Context, e Executor) error {
+	if err := e.ExecuteCDP(ctx, "Profiler.stopPreciseCoverage", nil, nil); err != nil {
+		return err
+	}
+	return e.ExecuteCDP(ctx, "Profiler.disable", nil, nil)
+}
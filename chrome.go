@@ -0,0 +1,170 @@
+package navigator
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// ChromeConfig is the configuration of Chrome passed to chromedriver under the
+// goog:chromeOptions capability.
+//
+// See: https://chromedriver.chromium.org/capabilities
+type ChromeConfig struct {
+	// Args are the command-line arguments of Chrome (ex. "--no-sandbox").
+	Args []string
+	// Binary is the path to the Chrome executable.
+	Binary string
+	// Extensions are the paths to the packed extensions (.crx) to install.
+	Extensions []string
+	// Prefs are the user preferences (ex. "download.default_directory").
+	Prefs map[string]any
+	// LocalState are the preferences of the Local State file.
+	LocalState map[string]any
+	// MobileEmulation is the configuration of the mobile emulation.
+	MobileEmulation *MobileEmulation
+	// ExcludeSwitches are the command-line switches chromedriver should not
+	// pass to Chrome, without the "--" prefix.
+	ExcludeSwitches []string
+	// Detach keeps Chrome running after chromedriver is stopped.
+	Detach bool
+	// DebuggerAddress is the address (host:port) of a running Chrome to connect to.
+	DebuggerAddress string
+	// WindowTypes are the window types (ex. "webview") that appear in the list
+	// of window handles.
+	WindowTypes []string
+	// PerfLoggingPrefs is the configuration of the performance log.
+	PerfLoggingPrefs *PerfLoggingPrefs
+}
+
+// EdgeConfig is the configuration of Microsoft Edge passed to msedgedriver under
+// the ms:edgeOptions capability. Chromium based Edge accepts the same options
+// as Chrome.
+//
+// See: https://learn.microsoft.com/en-us/microsoft-edge/webdriver-chromium/capabilities-edge-options
+type EdgeConfig = ChromeConfig
+
+// MobileEmulation is the configuration of the mobile emulation of Chrome.
+// Either DeviceName or DeviceMetrics should be specified.
+type MobileEmulation struct {
+	// DeviceName is a device name of the Chrome DevTools (ex. "Pixel 7").
+	DeviceName string `json:"deviceName,omitempty"`
+	// DeviceMetrics are the metrics of the emulated device.
+	DeviceMetrics *DeviceMetrics `json:"deviceMetrics,omitempty"`
+	// UserAgent is the user agent of the emulated device.
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+// DeviceMetrics are the metrics of the device emulated by Chrome.
+type DeviceMetrics struct {
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	PixelRatio float64 `json:"pixelRatio"`
+	Touch      bool    `json:"touch"`
+	Mobile     bool    `json:"mobile"`
+}
+
+// PerfLoggingPrefs is the configuration of the Chrome performance log.
+// The performance log has to be enabled with the LoggingPrefs Option.
+type PerfLoggingPrefs struct {
+	// EnableNetwork collects the events of the Network domain (default: true).
+	EnableNetwork *bool `json:"enableNetwork,omitempty"`
+	// EnablePage collects the events of the Page domain (default: true).
+	EnablePage *bool `json:"enablePage,omitempty"`
+	// TraceCategories is a comma-separated list of the trace categories.
+	TraceCategories string `json:"traceCategories,omitempty"`
+	// BufferUsageReportingInterval is the interval of the DevTools trace
+	// buffer usage events in milliseconds.
+	BufferUsageReportingInterval int `json:"bufferUsageReportingInterval,omitempty"`
+}
+
+func (c *ChromeConfig) validate() error {
+	if c.Binary != "" {
+		if _, err := os.Stat(c.Binary); err != nil {
+			return fmt.Errorf("invalid binary: %w", err)
+		}
+	}
+	if c.DebuggerAddress != "" {
+		if _, _, err := net.SplitHostPort(c.DebuggerAddress); err != nil {
+			return fmt.Errorf("invalid debugger address: %w", err)
+		}
+	}
+	if m := c.MobileEmulation; m != nil {
+		if m.DeviceName != "" && m.DeviceMetrics != nil {
+			return errors.New("invalid mobile emulation: specify either device name or device metrics")
+		}
+		if m.DeviceName == "" && m.DeviceMetrics == nil {
+			return errors.New("invalid mobile emulation: device name or device metrics is required")
+		}
+	}
+	if p := c.PerfLoggingPrefs; p != nil && p.BufferUsageReportingInterval < 0 {
+		return fmt.Errorf("invalid buffer usage reporting interval: %d", p.BufferUsageReportingInterval)
+	}
+	return nil
+}
+
+// options returns the configuration as the value of goog:chromeOptions.
+func (c *ChromeConfig) options() (map[string]any, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	opts := map[string]any{}
+	if len(c.Args) > 0 {
+		opts["args"] = toArgs(c.Args)
+	}
+	if c.Binary != "" {
+		opts["binary"] = c.Binary
+	}
+	if len(c.Extensions) > 0 {
+		var extensions []string
+		for _, v := range c.Extensions {
+			b, err := os.ReadFile(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid extension: %w", err)
+			}
+			extensions = append(extensions, base64.StdEncoding.EncodeToString(b))
+		}
+		opts["extensions"] = extensions
+	}
+	if c.Prefs != nil {
+		opts["prefs"] = c.Prefs
+	}
+	if c.LocalState != nil {
+		opts["localState"] = c.LocalState
+	}
+	if c.MobileEmulation != nil {
+		opts["mobileEmulation"] = c.MobileEmulation
+	}
+	if len(c.ExcludeSwitches) > 0 {
+		opts["excludeSwitches"] = c.ExcludeSwitches
+	}
+	if c.Detach {
+		opts["detach"] = true
+	}
+	if c.DebuggerAddress != "" {
+		opts["debuggerAddress"] = c.DebuggerAddress
+	}
+	if len(c.WindowTypes) > 0 {
+		opts["windowTypes"] = c.WindowTypes
+	}
+	if c.PerfLoggingPrefs != nil {
+		opts["perfLoggingPrefs"] = c.PerfLoggingPrefs
+	}
+	return opts, nil
+}
+
+// toArgs converts the args option, which may be either []string or []any, to []any.
+func toArgs(v any) []any {
+	var ret []any
+	switch v := v.(type) {
+	case []string:
+		for _, arg := range v {
+			ret = append(ret, arg)
+		}
+	case []any:
+		ret = append(ret, v...)
+	}
+	return ret
+}
@@ -1,8 +1,18 @@
 package navigator
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/ikawaha/navigator/devices"
+	"github.com/ikawaha/navigator/proxy"
+	"github.com/ikawaha/navigator/webdriver/session"
 )
 
 type config struct {
@@ -10,11 +20,41 @@ type config struct {
 	httpClient *http.Client
 	debug      *bool
 	timeout    *time.Duration
+	driverPath string
+	stdout     io.Writer
+	stderr     io.Writer
+	logFile    string
+	logPrefix  string
+	logPath    string
+	verbose    bool
+	host       string
+	port       int
+	minPort    int
+	maxPort    int
+	allowedIPs []string
+	fields     map[string]string
+	middleware []session.Middleware
+	logger     *slog.Logger
+
+	// page config
+	skipVersionCheck bool
 
 	// capabilities
 	browserName         string
 	rejectInvalidSSL    bool
-	chromeOptions       map[string]any // chrome driver config
+	chrome              *ChromeConfig
+	chromeOptions       map[string]any // untyped chrome driver config
+	firefox             *FirefoxConfig
+	edge                *EdgeConfig
+	webkit              *WebKitConfig
+	selenium            *SeleniumConfig
+	loggingPrefs        map[string]string
+	proxy               *proxy.Proxy
+	bidi                bool
+	headless            bool
+	device              *devices.Device
+	windowSize          *windowSize
+	emulation           *Emulation
 	desiredCapabilities Capabilities
 }
 
@@ -33,7 +73,7 @@ func newMergedConfig(config config, options []Option) config {
 	return config
 }
 
-func (c *config) capabilities() Capabilities {
+func (c *config) capabilities() (Capabilities, error) {
 	cb := Capabilities{"acceptSslCerts": true}
 	for feature, value := range c.desiredCapabilities {
 		cb[feature] = value
@@ -41,11 +81,216 @@ func (c *config) capabilities() Capabilities {
 	if c.browserName != "" {
 		cb.Browser(c.browserName)
 	}
-	if c.chromeOptions != nil {
-		cb["chromeOptions"] = c.chromeOptions
+	chromeOptions, err := c.chromeCapability()
+	if err != nil {
+		return nil, fmt.Errorf("invalid chrome options: %w", err)
+	}
+	if chromeOptions != nil {
+		cb["goog:chromeOptions"] = chromeOptions
+	}
+	edgeOptions, err := c.edgeCapability()
+	if err != nil {
+		return nil, fmt.Errorf("invalid edge options: %w", err)
+	}
+	if edgeOptions != nil {
+		cb["ms:edgeOptions"] = edgeOptions
+	}
+	firefoxOptions, err := c.firefoxCapability()
+	if err != nil {
+		return nil, fmt.Errorf("invalid firefox options: %w", err)
+	}
+	if firefoxOptions != nil {
+		cb["moz:firefoxOptions"] = firefoxOptions
+	}
+	if c.webkit != nil {
+		webkitOptions, err := c.webkit.options()
+		if err != nil {
+			return nil, fmt.Errorf("invalid webkit options: %w", err)
+		}
+		cb["webkitgtk:browserOptions"] = webkitOptions
+	}
+	if c.selenium != nil {
+		for k, v := range c.selenium.capabilities() {
+			cb[k] = v
+		}
+	}
+	if c.loggingPrefs != nil {
+		cb["goog:loggingPrefs"] = c.loggingPrefs
+	}
+	if c.proxy != nil {
+		addr := c.proxy.Addr()
+		// noProxy is a list, which geckodriver requires in both dialects.
+		// "<-loopback>" is the token of the Chrome bypass list which removes
+		// the implicit bypass of the loopback addresses, so that the requests
+		// to the servers on localhost also go through the proxy.
+		cb["proxy"] = map[string]any{
+			"proxyType": "manual",
+			"httpProxy": addr,
+			"sslProxy":  addr,
+			"noProxy":   []string{"<-loopback>"},
+		}
+		cb["acceptInsecureCerts"] = true
+	}
+	if c.bidi {
+		cb["webSocketUrl"] = true
 	}
 	if c.rejectInvalidSSL {
 		cb.Without("acceptSslCerts")
 	}
-	return cb
+	return cb, nil
+}
+
+type windowSize struct {
+	width  int
+	height int
+}
+
+// chromeCapability returns the value of goog:chromeOptions. The options
+// given by ChromeOptions take precedence over ChromeConfig, except args
+// which are appended.
+func (c *config) chromeCapability() (map[string]any, error) {
+	if c.chrome == nil && c.chromeOptions == nil && !c.chromiumEmulated() {
+		return nil, nil
+	}
+	return c.chromiumCapability(c.chrome, c.chromeOptions)
+}
+
+// edgeCapability returns the value of ms:edgeOptions.
+func (c *config) edgeCapability() (map[string]any, error) {
+	if c.edge == nil && !c.chromiumEmulated() {
+		return nil, nil
+	}
+	return c.chromiumCapability(c.edge, nil)
+}
+
+func (c *config) chromiumEmulated() bool {
+	return c.headless || c.device != nil || c.windowSize != nil
+}
+
+func (c *config) chromiumCapability(typed *ChromeConfig, untyped map[string]any) (map[string]any, error) {
+	opts := map[string]any{}
+	if typed != nil {
+		v, err := typed.options()
+		if err != nil {
+			return nil, err
+		}
+		opts = v
+	}
+	for k, v := range untyped {
+		if k == "args" {
+			v = append(toArgs(opts["args"]), toArgs(v)...)
+		}
+		opts[k] = v
+	}
+	var args []any
+	if c.headless {
+		args = append(args, "--headless=new")
+	}
+	if c.windowSize != nil {
+		args = append(args, fmt.Sprintf("--window-size=%d,%d", c.windowSize.width, c.windowSize.height))
+	}
+	if len(args) > 0 {
+		opts["args"] = append(toArgs(opts["args"]), args...)
+	}
+	if d := c.device; d != nil {
+		opts["mobileEmulation"] = &MobileEmulation{
+			DeviceMetrics: &DeviceMetrics{
+				Width:      d.Width,
+				Height:     d.Height,
+				PixelRatio: d.DeviceScaleFactor,
+				Touch:      d.Touch,
+				Mobile:     d.Mobile,
+			},
+			UserAgent: d.UserAgent,
+		}
+	}
+	return opts, nil
+}
+
+// firefoxCapability returns the value of moz:firefoxOptions. The preferences
+// and environment variables of the Device and Emulated Options take precedence
+// over FirefoxConfig.
+func (c *config) firefoxCapability() (map[string]any, error) {
+	if c.firefox == nil && !c.headless && c.device == nil && c.windowSize == nil && c.emulation == nil {
+		return nil, nil
+	}
+	opts := map[string]any{}
+	if c.firefox != nil {
+		typed, err := c.firefox.options()
+		if err != nil {
+			return nil, err
+		}
+		opts = typed
+	}
+	var args []string
+	if c.headless {
+		args = append(args, "-headless")
+	}
+	if c.windowSize != nil {
+		args = append(args, fmt.Sprintf("--width=%d", c.windowSize.width), fmt.Sprintf("--height=%d", c.windowSize.height))
+	}
+	prefs := map[string]any{}
+	env := map[string]string{}
+	if c.emulation != nil {
+		prefs, env = c.emulation.firefoxPrefs()
+	}
+	if d := c.device; d != nil {
+		prefs["general.useragent.override"] = d.UserAgent
+		prefs["layout.css.devPixelsPerPx"] = strconv.FormatFloat(d.DeviceScaleFactor, 'f', -1, 64)
+		prefs["dom.w3c_touch_events.enabled"] = boolToInt(d.Touch)
+	}
+	if len(args) > 0 {
+		typed, _ := opts["args"].([]string)
+		opts["args"] = append(append([]string{}, typed...), args...)
+	}
+	if len(prefs) > 0 {
+		merged := map[string]any{}
+		if typed, ok := opts["prefs"].(map[string]any); ok {
+			maps.Copy(merged, typed)
+		}
+		maps.Copy(merged, prefs)
+		opts["prefs"] = merged
+	}
+	if len(env) > 0 {
+		merged := map[string]string{}
+		if typed, ok := opts["env"].(map[string]string); ok {
+			maps.Copy(merged, typed)
+		}
+		maps.Copy(merged, env)
+		opts["env"] = merged
+	}
+	return opts, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// emulate emulates the environment of the Emulated Option on the new page.
+func (c *config) emulate(ctx context.Context, p *Page) error {
+	e := c.emulation
+	if e == nil {
+		return nil
+	}
+	if err := e.validate(); err != nil {
+		return fmt.Errorf("failed to emulate: %w", err)
+	}
+	if !p.session.IsChromium() {
+		// Firefox emulates the environment on launch.
+		if e.MediaType != "" {
+			return fmt.Errorf("failed to emulate: %w: media type emulation on %q", session.ErrUnsupported, p.session.BrowserName())
+		}
+		return nil
+	}
+	if err := emulateCDP(ctx, p.session, *e); err != nil {
+		return fmt.Errorf("failed to emulate: %w", err)
+	}
+	// the environment of the Option is kept across Reset and ResetEmulation.
+	p.mu.Lock()
+	p.baseEmulation = e
+	p.mu.Unlock()
+	return nil
 }
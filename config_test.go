@@ -0,0 +1,216 @@
+package navigator
+
+import (
+	"encoding/base64"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ikawaha/navigator/devices"
+	"github.com/ikawaha/navigator/proxy"
+)
+
+func Test_config_capabilities(t *testing.T) {
+	hello, err := os.ReadFile("testdata/hello.html")
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: unexpected error %v", err)
+	}
+	helloBase64 := base64.StdEncoding.EncodeToString(hello)
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    Capabilities
+		wantErr bool
+	}{
+		{
+			name:    "default",
+			options: nil,
+			want:    Capabilities{"acceptSslCerts": true},
+		},
+		{
+			name:    "headless with window size",
+			options: []Option{ChromeOptions("args", []string{"--no-sandbox"}), Headless, WindowSize(800, 600)},
+			want: Capabilities{
+				"acceptSslCerts": true,
+				"goog:chromeOptions": map[string]any{
+					"args": []any{"--no-sandbox", "--headless=new", "--window-size=800,600"},
+				},
+				"ms:edgeOptions": map[string]any{
+					"args": []any{"--headless=new", "--window-size=800,600"},
+				},
+				"moz:firefoxOptions": map[string]any{
+					"args": []string{"-headless", "--width=800", "--height=600"},
+				},
+			},
+		},
+		{
+			name:    "device",
+			options: []Option{Device(devices.Pixel7)},
+			want: Capabilities{
+				"acceptSslCerts": true,
+				"goog:chromeOptions": map[string]any{
+					"mobileEmulation": &MobileEmulation{
+						DeviceMetrics: &DeviceMetrics{
+							Width:      412,
+							Height:     915,
+							PixelRatio: 2.625,
+							Touch:      true,
+							Mobile:     true,
+						},
+						UserAgent: devices.Pixel7.UserAgent,
+					},
+				},
+				"ms:edgeOptions": map[string]any{
+					"mobileEmulation": &MobileEmulation{
+						DeviceMetrics: &DeviceMetrics{
+							Width:      412,
+							Height:     915,
+							PixelRatio: 2.625,
+							Touch:      true,
+							Mobile:     true,
+						},
+						UserAgent: devices.Pixel7.UserAgent,
+					},
+				},
+				"moz:firefoxOptions": map[string]any{
+					"prefs": map[string]any{
+						"general.useragent.override":   devices.Pixel7.UserAgent,
+						"layout.css.devPixelsPerPx":    "2.625",
+						"dom.w3c_touch_events.enabled": 1,
+					},
+				},
+			},
+		},
+		{
+			name: "emulated",
+			options: []Option{Emulated(Emulation{
+				Timezone:    "Asia/Tokyo",
+				Locale:      "ja-JP",
+				ColorScheme: ColorSchemeDark,
+			})},
+			want: Capabilities{
+				"acceptSslCerts": true,
+				"moz:firefoxOptions": map[string]any{
+					"prefs": map[string]any{
+						"intl.locale.requested":                            "ja-JP",
+						"intl.accept_languages":                            "ja-JP",
+						"javascript.use_us_english_locale":                 false,
+						"layout.css.prefers-color-scheme.content-override": 0,
+						"ui.systemUsesDarkTheme":                           1,
+					},
+					"env": map[string]string{"TZ": "Asia/Tokyo"},
+				},
+			},
+		},
+		{
+			name: "chrome config",
+			options: []Option{
+				Chrome(ChromeConfig{
+					Args:            []string{"--no-sandbox"},
+					Extensions:      []string{"testdata/hello.html"},
+					Prefs:           map[string]any{"download.default_directory": "/tmp"},
+					ExcludeSwitches: []string{"enable-automation"},
+					DebuggerAddress: "127.0.0.1:9222",
+					MobileEmulation: &MobileEmulation{DeviceName: "Pixel 7"},
+				}),
+				ChromeOptions("args", []string{"--disable-gpu"}),
+				ChromeOptions("prefs", map[string]any{"intl.accept_languages": "ja"}),
+			},
+			want: Capabilities{
+				"acceptSslCerts": true,
+				"goog:chromeOptions": map[string]any{
+					"args":            []any{"--no-sandbox", "--disable-gpu"},
+					"extensions":      []string{helloBase64},
+					"prefs":           map[string]any{"intl.accept_languages": "ja"},
+					"excludeSwitches": []string{"enable-automation"},
+					"debuggerAddress": "127.0.0.1:9222",
+					"mobileEmulation": &MobileEmulation{DeviceName: "Pixel 7"},
+				},
+			},
+		},
+		{
+			name:    "edge config",
+			options: []Option{Edge(EdgeConfig{Args: []string{"--inprivate"}})},
+			want: Capabilities{
+				"acceptSslCerts": true,
+				"ms:edgeOptions": map[string]any{
+					"args": []any{"--inprivate"},
+				},
+			},
+		},
+		{
+			name: "webkit config",
+			options: []Option{WebKit(WebKitConfig{
+				Args:         []string{"--automation"},
+				Certificates: []WebKitCertificate{{Host: "localhost", CertificateFile: "testdata/hello.html"}},
+			})},
+			want: Capabilities{
+				"acceptSslCerts": true,
+				"webkitgtk:browserOptions": map[string]any{
+					"args":         []string{"--automation"},
+					"certificates": []WebKitCertificate{{Host: "localhost", CertificateFile: "testdata/hello.html"}},
+				},
+			},
+		},
+		{
+			name:    "invalid webkit certificate",
+			options: []Option{WebKit(WebKitConfig{Certificates: []WebKitCertificate{{Host: "localhost", CertificateFile: "testdata/not_found.pem"}}})},
+			wantErr: true,
+		},
+		{
+			name: "selenium config",
+			options: []Option{
+				Browser("firefox"),
+				Selenium(SeleniumConfig{Name: "login test", RecordVideo: true}),
+			},
+			want: Capabilities{
+				"acceptSslCerts": true,
+				"browserName":    "firefox",
+				"se:name":        "login test",
+				"se:recordVideo": true,
+			},
+		},
+		{
+			name:    "proxy",
+			options: []Option{UseProxy(proxy.New())},
+			want: Capabilities{
+				"acceptSslCerts":      true,
+				"acceptInsecureCerts": true,
+				"proxy": map[string]any{
+					"proxyType": "manual",
+					"httpProxy": "",
+					"sslProxy":  "",
+					"noProxy":   []string{"<-loopback>"},
+				},
+			},
+		},
+		{
+			name:    "invalid extension",
+			options: []Option{Chrome(ChromeConfig{Extensions: []string{"testdata/not_found.crx"}})},
+			wantErr: true,
+		},
+		{
+			name:    "invalid mobile emulation",
+			options: []Option{Chrome(ChromeConfig{MobileEmulation: &MobileEmulation{}})},
+			wantErr: true,
+		},
+		{
+			name:    "invalid debugger address",
+			options: []Option{Chrome(ChromeConfig{DebuggerAddress: "9222"})},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConfig(tt.options)
+			got, err := c.capabilities()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("capabilities() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
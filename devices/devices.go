@@ -0,0 +1,181 @@
+package devices
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Device is a profile of a device.
+type Device struct {
+	// Name is the name of the device (ex. "Pixel 7").
+	Name string
+	// Width is the width of the viewport in CSS pixels.
+	Width int
+	// Height is the height of the viewport in CSS pixels.
+	Height int
+	// DeviceScaleFactor is the device pixel ratio.
+	DeviceScaleFactor float64
+	// UserAgent is the user agent of the browser on the device.
+	UserAgent string
+	// Touch reports whether the device has a touch screen.
+	Touch bool
+	// Mobile reports whether the device is a mobile device.
+	Mobile bool
+}
+
+// Landscape returns the device rotated to the landscape orientation.
+func (d Device) Landscape() Device {
+	if d.Width < d.Height {
+		d.Width, d.Height = d.Height, d.Width
+	}
+	return d
+}
+
+var (
+	// Pixel5 is the profile of Google Pixel 5.
+	Pixel5 = Device{
+		Name:              "Pixel 5",
+		Width:             393,
+		Height:            851,
+		DeviceScaleFactor: 2.75,
+		UserAgent:         "Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+		Touch:             true,
+		Mobile:            true,
+	}
+
+	// Pixel7 is the profile of Google Pixel 7.
+	Pixel7 = Device{
+		Name:              "Pixel 7",
+		Width:             412,
+		Height:            915,
+		DeviceScaleFactor: 2.625,
+		UserAgent:         "Mozilla/5.0 (Linux; Android 14; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+		Touch:             true,
+		Mobile:            true,
+	}
+
+	// GalaxyS20 is the profile of Samsung Galaxy S20.
+	GalaxyS20 = Device{
+		Name:              "Galaxy S20",
+		Width:             360,
+		Height:            800,
+		DeviceScaleFactor: 3,
+		UserAgent:         "Mozilla/5.0 (Linux; Android 13; SM-G981B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+		Touch:             true,
+		Mobile:            true,
+	}
+
+	// IPhoneSE is the profile of Apple iPhone SE (3rd generation).
+	IPhoneSE = Device{
+		Name:              "iPhone SE",
+		Width:             375,
+		Height:            667,
+		DeviceScaleFactor: 2,
+		UserAgent:         "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		Touch:             true,
+		Mobile:            true,
+	}
+
+	// IPhone14 is the profile of Apple iPhone 14.
+	IPhone14 = Device{
+		Name:              "iPhone 14",
+		Width:             390,
+		Height:            844,
+		DeviceScaleFactor: 3,
+		UserAgent:         "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		Touch:             true,
+		Mobile:            true,
+	}
+
+	// IPhone14ProMax is the profile of Apple iPhone 14 Pro Max.
+	IPhone14ProMax = Device{
+		Name:              "iPhone 14 Pro Max",
+		Width:             430,
+		Height:            932,
+		DeviceScaleFactor: 3,
+		UserAgent:         "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		Touch:             true,
+		Mobile:            true,
+	}
+
+	// IPadMini is the profile of Apple iPad mini (6th generation).
+	IPadMini = Device{
+		Name:              "iPad Mini",
+		Width:             744,
+		Height:            1133,
+		DeviceScaleFactor: 2,
+		UserAgent:         "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		Touch:             true,
+		Mobile:            true,
+	}
+
+	// IPadPro11 is the profile of Apple iPad Pro 11.
+	IPadPro11 = Device{
+		Name:              "iPad Pro 11",
+		Width:             834,
+		Height:            1194,
+		DeviceScaleFactor: 2,
+		UserAgent:         "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+		Touch:             true,
+		Mobile:            true,
+	}
+
+	// GalaxyTabS4 is the profile of Samsung Galaxy Tab S4.
+	GalaxyTabS4 = Device{
+		Name:              "Galaxy Tab S4",
+		Width:             712,
+		Height:            1138,
+		DeviceScaleFactor: 2.25,
+		UserAgent:         "Mozilla/5.0 (Linux; Android 10; SM-T837A) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		Touch:             true,
+		Mobile:            true,
+	}
+)
+
+var registry = struct {
+	sync.RWMutex
+	devices map[string]Device
+}{
+	devices: map[string]Device{},
+}
+
+func init() {
+	for _, d := range []Device{
+		Pixel5, Pixel7, GalaxyS20,
+		IPhoneSE, IPhone14, IPhone14ProMax,
+		IPadMini, IPadPro11, GalaxyTabS4,
+	} {
+		Register(d)
+	}
+}
+
+// Register registers the device to the registry. A device with the same
+// name (case-insensitive) is replaced.
+func Register(d Device) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.devices[strings.ToLower(d.Name)] = d
+}
+
+// Lookup returns the registered device by name (case-insensitive).
+func Lookup(name string) (Device, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	d, ok := registry.devices[strings.ToLower(name)]
+	return d, ok
+}
+
+// All returns all registered devices sorted by name.
+func All() []Device {
+	registry.RLock()
+	defer registry.RUnlock()
+	ret := make([]Device, 0, len(registry.devices))
+	for _, d := range registry.devices {
+		ret = append(ret, d)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
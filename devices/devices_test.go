@@ -0,0 +1,35 @@
+package devices
+
+import (
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	d, ok := Lookup("pixel 7")
+	if !ok {
+		t.Fatalf("want Pixel 7 registered, but not")
+	}
+	if d != Pixel7 {
+		t.Errorf("want %+v, got %+v", Pixel7, d)
+	}
+	if _, ok := Lookup("unknown"); ok {
+		t.Errorf("want unknown not registered, but registered")
+	}
+	Register(Device{Name: "Custom", Width: 100, Height: 200})
+	if _, ok := Lookup("CUSTOM"); !ok {
+		t.Errorf("want Custom registered, but not")
+	}
+	if got := len(All()); got < 10 {
+		t.Errorf("want at least 10 devices, got %d", got)
+	}
+}
+
+func TestDevice_Landscape(t *testing.T) {
+	got := IPhone14.Landscape()
+	if got.Width != IPhone14.Height || got.Height != IPhone14.Width {
+		t.Errorf("want %dx%d, got %dx%d", IPhone14.Height, IPhone14.Width, got.Width, got.Height)
+	}
+	if again := got.Landscape(); again != got {
+		t.Errorf("want %+v, got %+v", got, again)
+	}
+}
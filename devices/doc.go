@@ -0,0 +1,3 @@
+// Package devices is a registry of device profiles for emulating phones and
+// tablets in the browser.
+package devices
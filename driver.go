@@ -0,0 +1,171 @@
+package navigator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/ikawaha/navigator/internal/browser"
+)
+
+// ErrVersionMismatch is returned when the major versions of the web driver
+// and the browser differ, which makes the driver fail with obscure errors
+// such as "invalid session id".
+var ErrVersionMismatch = errors.New("browser and driver versions mismatch")
+
+// driverEnvPrefix is the prefix of the environment variables which specify
+// the path to the driver (ex. NAVIGATOR_CHROMEDRIVER) or the browser
+// (ex. NAVIGATOR_CHROME).
+const driverEnvPrefix = "NAVIGATOR_"
+
+// browserSpec is the specification of the browser driven by a web driver
+// whose major version has to match the one of the browser.
+type browserSpec struct {
+	// name is the display name of the browser.
+	name string
+	// env is the suffix of the environment variable of the browser path.
+	env string
+	// candidates are the executable names or paths of the browser on the platform.
+	candidates []string
+	// binary returns the browser path configured by the Options.
+	binary func(c *config) string
+}
+
+var chromeSpec = &browserSpec{
+	name:       "Chrome",
+	env:        "CHROME",
+	candidates: browser.ChromeCandidates(runtime.GOOS),
+	binary: func(c *config) string {
+		if s, ok := c.chromeOptions["binary"].(string); ok && s != "" {
+			return s
+		}
+		if c.chrome != nil {
+			return c.chrome.Binary
+		}
+		return ""
+	},
+}
+
+var edgeSpec = &browserSpec{
+	name:       "Microsoft Edge",
+	env:        "MSEDGE",
+	candidates: edgeCandidates(),
+	binary: func(c *config) string {
+		if c.edge != nil {
+			return c.edge.Binary
+		}
+		return ""
+	},
+}
+
+func edgeCandidates() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge"}
+	case "windows":
+		// msedge.exe does not print its version to the console.
+		return nil
+	default:
+		return []string{"microsoft-edge", "microsoft-edge-stable", "msedge"}
+	}
+}
+
+// lookupDriver returns the path to the driver executable. The explicit path
+// given by the DriverPath Option takes precedence over the environment
+// variable (ex. NAVIGATOR_CHROMEDRIVER), which takes precedence over PATH.
+// It returns the executable name if the driver is not found, so that
+// starting the service reports the error.
+func lookupDriver(name, explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if v := os.Getenv(driverEnvPrefix + strings.ToUpper(name)); v != "" {
+		return v
+	}
+	if p, err := exec.LookPath(executable(name)); err == nil {
+		return p
+	}
+	return executable(name)
+}
+
+// lookup returns the path to the browser executable, or an empty
+// string if the browser is not found.
+func (b *browserSpec) lookup(c *config) string {
+	if v := b.binary(c); v != "" {
+		return v
+	}
+	if v := os.Getenv(driverEnvPrefix + b.env); v != "" {
+		return v
+	}
+	for _, v := range b.candidates {
+		if filepath.IsAbs(v) {
+			if _, err := os.Stat(v); err == nil {
+				return v
+			}
+			continue
+		}
+		if p, err := exec.LookPath(v); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// majorVersion returns the major version in the output of --version
+// (ex. "ChromeDriver 120.0.6099.109 (3419140ab6...)").
+func majorVersion(output string) (int, bool) {
+	m := browser.VersionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return 0, false
+	}
+	major, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return major, true
+}
+
+// version runs the executable with --version and returns the trimmed output.
+func version(ctx context.Context, path string) (string, error) {
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s --version: %w", path, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// checkVersion fails if the major versions of the driver and the browser differ.
+// It skips the check if the browser is not found or its version is unknown,
+// because the driver may launch the browser by itself.
+func checkVersion(ctx context.Context, driver, browser string, spec *browserSpec) error {
+	if browser == "" {
+		return nil
+	}
+	bv, err := version(ctx, browser)
+	if err != nil {
+		return nil
+	}
+	bMajor, ok := majorVersion(bv)
+	if !ok {
+		return nil
+	}
+	dv, err := version(ctx, driver)
+	if err != nil {
+		return fmt.Errorf("failed to check driver version: %w", err)
+	}
+	dMajor, ok := majorVersion(dv)
+	if !ok {
+		return fmt.Errorf("failed to check driver version: unexpected output %q", dv)
+	}
+	if dMajor != bMajor {
+		return fmt.Errorf("%w: %s (%s) supports %s %d, but %s (%s) is %s %d; install the driver matching the browser major version",
+			ErrVersionMismatch, driver, dv, spec.name, dMajor, browser, bv, spec.name, bMajor)
+	}
+	return nil
+}
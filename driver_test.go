@@ -0,0 +1,93 @@
+package navigator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func Test_lookupDriver(t *testing.T) {
+	t.Setenv("NAVIGATOR_CHROMEDRIVER", "/opt/chromedriver")
+	if got, want := lookupDriver("chromedriver", "/usr/local/bin/chromedriver"), "/usr/local/bin/chromedriver"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := lookupDriver("chromedriver", ""), "/opt/chromedriver"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	t.Setenv("NAVIGATOR_CHROMEDRIVER", "")
+	t.Setenv("PATH", t.TempDir())
+	if got, want := lookupDriver("chromedriver", ""), executable("chromedriver"); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func Test_majorVersion(t *testing.T) {
+	testdata := []struct {
+		output string
+		want   int
+		ok     bool
+	}{
+		{output: "ChromeDriver 120.0.6099.109 (3419140ab665596f21b385ce136419fde0924272-refs/branch-heads/6099@{#1483})", want: 120, ok: true},
+		{output: "Google Chrome 121.0.6167.85", want: 121, ok: true},
+		{output: "Microsoft Edge 120.0.2210.91", want: 120, ok: true},
+		{output: "Chromium", ok: false},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.output, func(t *testing.T) {
+			got, ok := majorVersion(tt.output)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("want (%d, %t), got (%d, %t)", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func Test_checkVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	dir := t.TempDir()
+	script := func(name, output string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\necho '"+output+"'\n"), 0o755); err != nil {
+			t.Fatalf("os.WriteFile() failed: unexpected error %v", err)
+		}
+		return path
+	}
+	driver := script("chromedriver", "ChromeDriver 120.0.6099.109 (3419140ab665596f21b385ce136419fde0924272)")
+	ctx := context.Background()
+
+	t.Run("match", func(t *testing.T) {
+		browser := script("chrome-120", "Google Chrome 120.0.6099.129")
+		if err := checkVersion(ctx, driver, browser, chromeSpec); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		browser := script("chrome-121", "Google Chrome 121.0.6167.85")
+		err := checkVersion(ctx, driver, browser, chromeSpec)
+		if !errors.Is(err, ErrVersionMismatch) {
+			t.Errorf("want %v, got %v", ErrVersionMismatch, err)
+		}
+	})
+	t.Run("browser not found", func(t *testing.T) {
+		if err := checkVersion(ctx, driver, "", chromeSpec); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("page config", func(t *testing.T) {
+		browser := script("chrome-119", "Google Chrome 119.0.6045.199")
+		d := ChromeDriver(DriverPath(driver))
+		c := newMergedConfig(d.defaultConfig, []Option{Chrome(ChromeConfig{Binary: browser})})
+		if err := d.checkVersion(ctx, &c); !errors.Is(err, ErrVersionMismatch) {
+			t.Errorf("want %v, got %v", ErrVersionMismatch, err)
+		}
+		c = newMergedConfig(c, []Option{SkipVersionCheck})
+		if err := d.checkVersion(ctx, &c); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
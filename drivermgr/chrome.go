@@ -0,0 +1,60 @@
+package drivermgr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ikawaha/navigator/internal/browser"
+)
+
+// ChromeDriver returns the path to the chromedriver matching the installed
+// Chrome, downloading it if it is not cached.
+func (m *Manager) ChromeDriver(ctx context.Context) (string, error) {
+	goos, _ := m.platform()
+	binary, err := lookupBrowser(m.ChromeBinary, browser.ChromeCandidates(goos))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve chromedriver: %w", err)
+	}
+	version, err := browserVersion(ctx, binary)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve chromedriver: %w", err)
+	}
+	return m.ChromeDriverFor(ctx, version)
+}
+
+// ChromeDriverFor returns the path to the chromedriver matching the Chrome
+// version (ex. "120.0.6099.109" or "120"), downloading it if it is not cached.
+func (m *Manager) ChromeDriverFor(ctx context.Context, chromeVersion string) (string, error) {
+	platform, err := m.chromePlatform()
+	if err != nil {
+		return "", err
+	}
+	version, err := m.getString(ctx, fmt.Sprintf("%s/LATEST_RELEASE_%s", m.ChromeVersionURL, major(chromeVersion)))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve chromedriver version for Chrome %s: %w", chromeVersion, err)
+	}
+	url := fmt.Sprintf("%s/%s/%s/chromedriver-%s.zip", m.ChromeBaseURL, version, platform, platform)
+	path, err := m.install(ctx, "chromedriver", version, platform, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to install chromedriver %s: %w", version, err)
+	}
+	return path, nil
+}
+
+// chromePlatform returns the platform name of Chrome for Testing.
+func (m *Manager) chromePlatform() (string, error) {
+	goos, goarch := m.platform()
+	switch {
+	case goos == "linux" && goarch == "amd64":
+		return "linux64", nil
+	case goos == "darwin" && goarch == "amd64":
+		return "mac-x64", nil
+	case goos == "darwin" && goarch == "arm64":
+		return "mac-arm64", nil
+	case goos == "windows" && goarch == "386":
+		return "win32", nil
+	case goos == "windows" && goarch == "amd64":
+		return "win64", nil
+	}
+	return "", fmt.Errorf("chromedriver is not available for %s/%s", goos, goarch)
+}
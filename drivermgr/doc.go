@@ -0,0 +1,19 @@
+// Package drivermgr downloads the web driver matching the installed browser,
+// verifies its checksum, unpacks it, and caches it under the user cache
+// directory. The returned path is passed to navigator.ChromeDriver or
+// navigator.GeckoDriver with the navigator.DriverPath Option:
+//
+//	m := drivermgr.New()
+//	m.Checksums = map[string]string{
+//		"https://storage.googleapis.com/chrome-for-testing-public/120.0.6099.109/linux64/chromedriver-linux64.zip": "<sha256>",
+//	}
+//	path, err := m.ChromeDriver(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	driver := navigator.ChromeDriver(navigator.DriverPath(path))
+//
+// The public mirrors do not serve the checksum files, so the downloads fail
+// with ErrChecksumNotFound unless the checksums are given or the verification
+// is skipped with Manager.InsecureSkipChecksum.
+package drivermgr
@@ -0,0 +1,90 @@
+package drivermgr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+var firefoxCandidates = map[string][]string{
+	"darwin": {"/Applications/Firefox.app/Contents/MacOS/firefox"},
+	"linux":  {"firefox", "firefox-esr"},
+}
+
+// geckoVersions are the geckodriver versions and the minimum Firefox major
+// versions they support, in descending order.
+//
+// See: https://firefox-source-docs.mozilla.org/testing/geckodriver/Support.html
+var geckoVersions = []struct {
+	driver     string
+	minFirefox int
+}{
+	{driver: "0.35.0", minFirefox: 115},
+	{driver: "0.33.0", minFirefox: 102},
+	{driver: "0.31.0", minFirefox: 91},
+	{driver: "0.30.0", minFirefox: 78},
+}
+
+// GeckoDriver returns the path to the geckodriver supporting the installed
+// Firefox, downloading it if it is not cached.
+func (m *Manager) GeckoDriver(ctx context.Context) (string, error) {
+	goos, _ := m.platform()
+	binary, err := lookupBrowser(m.FirefoxBinary, firefoxCandidates[goos])
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve geckodriver: %w", err)
+	}
+	version, err := browserVersion(ctx, binary)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve geckodriver: %w", err)
+	}
+	return m.GeckoDriverFor(ctx, version)
+}
+
+// GeckoDriverFor returns the path to the geckodriver supporting the Firefox
+// version (ex. "128.0" or "128"), downloading it if it is not cached.
+func (m *Manager) GeckoDriverFor(ctx context.Context, firefoxVersion string) (string, error) {
+	platform, ext, err := m.geckoPlatform()
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(major(firefoxVersion))
+	if err != nil {
+		return "", fmt.Errorf("invalid Firefox version: %q", firefoxVersion)
+	}
+	var version string
+	for _, v := range geckoVersions {
+		if n >= v.minFirefox {
+			version = v.driver
+			break
+		}
+	}
+	if version == "" {
+		return "", fmt.Errorf("no geckodriver supports Firefox %s", firefoxVersion)
+	}
+	url := fmt.Sprintf("%s/v%s/geckodriver-v%s-%s%s", m.GeckoBaseURL, version, version, platform, ext)
+	path, err := m.install(ctx, "geckodriver", version, platform, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to install geckodriver %s: %w", version, err)
+	}
+	return path, nil
+}
+
+// geckoPlatform returns the platform name and the archive extension of the geckodriver releases.
+func (m *Manager) geckoPlatform() (platform, ext string, err error) {
+	goos, goarch := m.platform()
+	switch {
+	case goos == "linux" && goarch == "amd64":
+		return "linux64", ".tar.gz", nil
+	case goos == "linux" && goarch == "arm64":
+		return "linux-aarch64", ".tar.gz", nil
+	case goos == "darwin" && goarch == "amd64":
+		return "macos", ".tar.gz", nil
+	case goos == "darwin" && goarch == "arm64":
+		return "macos-aarch64", ".tar.gz", nil
+	case goos == "windows" && goarch == "amd64":
+		return "win64", ".zip", nil
+	case goos == "windows" && goarch == "arm64":
+		return "win-aarch64", ".zip", nil
+	}
+	return "", "", fmt.Errorf("geckodriver is not available for %s/%s", goos, goarch)
+}
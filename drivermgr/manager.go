@@ -0,0 +1,357 @@
+package drivermgr
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/ikawaha/navigator/internal/browser"
+)
+
+const (
+	// DefaultChromeBaseURL is the base URL of the Chrome for Testing downloads.
+	DefaultChromeBaseURL = "https://storage.googleapis.com/chrome-for-testing-public"
+	// DefaultChromeVersionURL is the base URL of the Chrome for Testing version endpoints.
+	DefaultChromeVersionURL = "https://googlechromelabs.github.io/chrome-for-testing"
+	// DefaultGeckoBaseURL is the base URL of the geckodriver releases.
+	DefaultGeckoBaseURL = "https://github.com/mozilla/geckodriver/releases/download"
+)
+
+var (
+	// ErrChecksumMismatch is returned when the downloaded archive does not match its checksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrChecksumNotFound is returned when the checksum of the archive is
+	// neither given nor served by the mirror.
+	ErrChecksumNotFound = errors.New("checksum not found")
+)
+
+// Manager resolves, downloads and caches web drivers.
+//
+// The downloads are verified by default: the checksum of an archive has to
+// be given in Checksums or served by the mirror as {archive URL}.sha256.
+// Neither the Chrome for Testing storage nor the geckodriver releases on
+// GitHub serve the checksum files, so the downloads from the public mirrors
+// fail with ErrChecksumNotFound unless their checksums are given, or the
+// verification is skipped with InsecureSkipChecksum.
+type Manager struct {
+	// ChromeBaseURL is the base URL of the chromedriver archives, laid out as
+	// {ChromeBaseURL}/{version}/{platform}/chromedriver-{platform}.zip.
+	ChromeBaseURL string
+	// ChromeVersionURL is the base URL of the LATEST_RELEASE_{major} files
+	// which resolve the chromedriver version of a Chrome major version.
+	ChromeVersionURL string
+	// GeckoBaseURL is the base URL of the geckodriver archives, laid out as
+	// {GeckoBaseURL}/v{version}/geckodriver-v{version}-{platform}.tar.gz (.zip on windows).
+	GeckoBaseURL string
+	// CacheDir is the directory of the downloaded drivers. The default is
+	// navigator/drivers under the user cache directory.
+	CacheDir string
+	// HTTPClient is used to download the drivers. The default is http.DefaultClient.
+	HTTPClient *http.Client
+	// Checksums are the SHA-256 checksums in hex of the archives keyed by URL.
+	// If the checksum of an archive is not given, the {archive URL}.sha256
+	// file of the mirror is used.
+	Checksums map[string]string
+	// InsecureSkipChecksum installs the archives whose checksums are neither
+	// given nor served by the mirror without verification.
+	InsecureSkipChecksum bool
+	// ChromeBinary and FirefoxBinary are the paths to the installed browsers.
+	// They are looked up from PATH and the default install locations if empty.
+	ChromeBinary  string
+	FirefoxBinary string
+
+	goos   string
+	goarch string
+}
+
+// New returns a Manager which downloads the drivers from the public mirrors.
+func New() *Manager {
+	return &Manager{
+		ChromeBaseURL:    DefaultChromeBaseURL,
+		ChromeVersionURL: DefaultChromeVersionURL,
+		GeckoBaseURL:     DefaultGeckoBaseURL,
+		HTTPClient:       http.DefaultClient,
+		goos:             runtime.GOOS,
+		goarch:           runtime.GOARCH,
+	}
+}
+
+func (m *Manager) client() *http.Client {
+	if m.HTTPClient != nil {
+		return m.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (m *Manager) platform() (goos, goarch string) {
+	if m.goos == "" {
+		return runtime.GOOS, runtime.GOARCH
+	}
+	return m.goos, m.goarch
+}
+
+func (m *Manager) cacheDir() (string, error) {
+	if m.CacheDir != "" {
+		return m.CacheDir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "navigator", "drivers"), nil
+}
+
+// install returns the cached driver executable, or downloads the archive
+// from the URL and unpacks the executable into the cache.
+func (m *Manager) install(ctx context.Context, name, version, platform, url string) (string, error) {
+	dir, err := m.cacheDir()
+	if err != nil {
+		return "", err
+	}
+	goos, _ := m.platform()
+	exe := name
+	if goos == "windows" {
+		exe += ".exe"
+	}
+	version = strings.TrimSpace(version)
+	if !driverVersionPattern.MatchString(version) {
+		return "", fmt.Errorf("invalid %s version: %q", name, version)
+	}
+	dst := filepath.Join(dir, name, version, platform, exe)
+	if _, err := os.Stat(dst); err == nil {
+		return dst, nil
+	}
+	archive, err := m.download(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(archive)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// unpack into a unique file in the same directory, so that concurrent
+	// installs do not clobber each other and the rename is atomic.
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+exe+"-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to install %s: %w", name, err)
+	}
+	if strings.HasSuffix(url, ".zip") {
+		err = unzip(archive, exe, tmp)
+	} else {
+		err = untar(archive, exe, tmp)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to unpack %s: %w", url, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to install %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to install %s: %w", name, err)
+	}
+	return dst, nil
+}
+
+// driverVersionPattern matches the driver versions, which are used as a
+// directory name of the cache.
+var driverVersionPattern = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// download downloads the archive into a temporary file and verifies its checksum.
+func (m *Manager) download(ctx context.Context, url string) (string, error) {
+	want, err := m.checksum(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	body, err := m.get(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	f, err := os.CreateTemp("", "navigator-driver-*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), body); err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); want != "" && !strings.EqualFold(got, want) {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("%w: %s: want %s, got %s", ErrChecksumMismatch, url, want, got)
+	}
+	return f.Name(), nil
+}
+
+// checksum returns the SHA-256 checksum of the archive, or an empty string
+// if the checksum is not available and the verification is skipped.
+func (m *Manager) checksum(ctx context.Context, url string) (string, error) {
+	if v, ok := m.Checksums[url]; ok {
+		return v, nil
+	}
+	body, err := m.get(ctx, url+".sha256")
+	if errors.Is(err, errNotFound) {
+		if m.InsecureSkipChecksum {
+			return "", nil
+		}
+		return "", fmt.Errorf("%w: %s", ErrChecksumNotFound, url)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve checksum: %w", err)
+	}
+	defer body.Close()
+	b, err := io.ReadAll(io.LimitReader(body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve checksum: %w", err)
+	}
+	// the format of sha256sum: "<checksum>  <file name>"
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("failed to retrieve checksum: empty %s.sha256", url)
+	}
+	return fields[0], nil
+}
+
+var errNotFound = errors.New("not found")
+
+func (m *Manager) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	resp, err := m.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", errNotFound, url)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		resp.Body.Close()
+		return nil, fmt.Errorf("request unsuccessful: %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (m *Manager) getString(ctx context.Context, url string) (string, error) {
+	body, err := m.get(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	b, err := io.ReadAll(io.LimitReader(body, 1024))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// unzip extracts the file named exe at any depth of the zip archive to dst.
+func unzip(archive, exe string, dst io.Writer) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || path.Base(f.Name) != exe {
+			continue
+		}
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(dst, src)
+		return err
+	}
+	return fmt.Errorf("%s not found in the archive", exe)
+}
+
+// untar extracts the file named exe at any depth of the tar.gz archive to dst.
+func untar(archive, exe string, dst io.Writer) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	r := tar.NewReader(gz)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s not found in the archive", exe)
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag == tar.TypeReg && path.Base(h.Name) == exe {
+			_, err = io.Copy(dst, r)
+			return err
+		}
+	}
+}
+
+// browserVersion runs the browser with --version and returns the version
+// (ex. "120.0.6099.109" of "Google Chrome 120.0.6099.109").
+func browserVersion(ctx context.Context, binary string) (string, error) {
+	out, err := exec.CommandContext(ctx, binary, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s --version: %w", binary, err)
+	}
+	v := browser.VersionPattern.FindString(string(out))
+	if v == "" {
+		return "", fmt.Errorf("failed to parse version of %s: %q", binary, out)
+	}
+	return v, nil
+}
+
+// lookupBrowser returns the binary if given, or the first candidate found.
+func lookupBrowser(binary string, candidates []string) (string, error) {
+	if binary != "" {
+		return binary, nil
+	}
+	for _, v := range candidates {
+		if filepath.IsAbs(v) {
+			if _, err := os.Stat(v); err == nil {
+				return v, nil
+			}
+			continue
+		}
+		if p, err := exec.LookPath(v); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("browser not found: %s", strings.Join(candidates, ", "))
+}
+
+func major(version string) string {
+	v, _, _ := strings.Cut(version, ".")
+	return v
+}
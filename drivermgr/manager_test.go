@@ -0,0 +1,193 @@
+package drivermgr
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func zipArchive(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	f, err := w.Create(name)
+	if err != nil {
+		t.Fatalf("zip.Create() failed: unexpected error %v", err)
+	}
+	_, _ = f.Write([]byte(content))
+	if err := w.Close(); err != nil {
+		t.Fatalf("zip.Close() failed: unexpected error %v", err)
+	}
+	return b.Bytes()
+}
+
+func tarGzArchive(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	w := tar.NewWriter(gz)
+	if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("tar.WriteHeader() failed: unexpected error %v", err)
+	}
+	_, _ = w.Write([]byte(content))
+	_ = w.Close()
+	_ = gz.Close()
+	return b.Bytes()
+}
+
+func sum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+type mirror struct {
+	*httptest.Server
+	files    map[string][]byte
+	requests atomic.Int32
+}
+
+func newMirror(files map[string][]byte) *mirror {
+	m := &mirror{files: files}
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.requests.Add(1)
+		b, ok := m.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(b)
+	}))
+	return m
+}
+
+func newTestManager(ts *mirror, goos, goarch string) *Manager {
+	return &Manager{
+		ChromeBaseURL:    ts.URL + "/chrome",
+		ChromeVersionURL: ts.URL + "/versions",
+		GeckoBaseURL:     ts.URL + "/gecko",
+		HTTPClient:       ts.Client(),
+		goos:             goos,
+		goarch:           goarch,
+	}
+}
+
+func TestManager_ChromeDriverFor(t *testing.T) {
+	archive := zipArchive(t, "chromedriver-linux64/chromedriver", "fake chromedriver")
+	const archivePath = "/chrome/120.0.6099.109/linux64/chromedriver-linux64.zip"
+	ts := newMirror(map[string][]byte{
+		"/versions/LATEST_RELEASE_120": []byte("120.0.6099.109\n"),
+		"/versions/LATEST_RELEASE_121": []byte("../../121.0.6167.85\n"),
+		archivePath:                    archive,
+		archivePath + ".sha256":        []byte(sum(archive) + "  chromedriver-linux64.zip\n"),
+	})
+	defer ts.Close()
+	ctx := context.Background()
+
+	m := newTestManager(ts, "linux", "amd64")
+	m.CacheDir = t.TempDir()
+	path, err := m.ChromeDriverFor(ctx, "120.0.6099.129")
+	if err != nil {
+		t.Fatalf("ChromeDriverFor() failed: unexpected error %v", err)
+	}
+	if !strings.HasPrefix(path, m.CacheDir) {
+		t.Errorf("want the path under %q, got %q", m.CacheDir, path)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: unexpected error %v", err)
+	}
+	if got, want := string(b), "fake chromedriver"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		before := ts.requests.Load()
+		got, err := m.ChromeDriverFor(ctx, "120")
+		if err != nil {
+			t.Fatalf("ChromeDriverFor() failed: unexpected error %v", err)
+		}
+		if got != path {
+			t.Errorf("want %q, got %q", path, got)
+		}
+		// only the version is resolved.
+		if n := ts.requests.Load() - before; n != 1 {
+			t.Errorf("want 1 request, got %d", n)
+		}
+	})
+	t.Run("checksum mismatch", func(t *testing.T) {
+		m := newTestManager(ts, "linux", "amd64")
+		m.CacheDir = t.TempDir()
+		m.Checksums = map[string]string{ts.URL + archivePath: sum([]byte("other"))}
+		if _, err := m.ChromeDriverFor(ctx, "120"); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("want %v, got %v", ErrChecksumMismatch, err)
+		}
+	})
+	t.Run("unknown version", func(t *testing.T) {
+		m := newTestManager(ts, "linux", "amd64")
+		m.CacheDir = t.TempDir()
+		if _, err := m.ChromeDriverFor(ctx, "99"); err == nil {
+			t.Errorf("expected error, but got nil")
+		}
+	})
+	t.Run("invalid version", func(t *testing.T) {
+		m := newTestManager(ts, "linux", "amd64")
+		m.CacheDir = t.TempDir()
+		if _, err := m.ChromeDriverFor(ctx, "121"); err == nil {
+			t.Errorf("expected error, but got nil")
+		}
+	})
+}
+
+func TestManager_GeckoDriverFor(t *testing.T) {
+	archive := tarGzArchive(t, "geckodriver", "fake geckodriver")
+	ts := newMirror(map[string][]byte{
+		"/gecko/v0.35.0/geckodriver-v0.35.0-linux64.tar.gz": archive,
+	})
+	defer ts.Close()
+	ctx := context.Background()
+
+	t.Run("skip checksum", func(t *testing.T) {
+		m := newTestManager(ts, "linux", "amd64")
+		m.CacheDir = t.TempDir()
+		m.InsecureSkipChecksum = true
+		path, err := m.GeckoDriverFor(ctx, "128.0")
+		if err != nil {
+			t.Fatalf("GeckoDriverFor() failed: unexpected error %v", err)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("os.ReadFile() failed: unexpected error %v", err)
+		}
+		if got, want := string(b), "fake geckodriver"; got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	})
+	t.Run("checksum not found", func(t *testing.T) {
+		m := newTestManager(ts, "linux", "amd64")
+		m.CacheDir = t.TempDir()
+		if _, err := m.GeckoDriverFor(ctx, "128.0"); !errors.Is(err, ErrChecksumNotFound) {
+			t.Errorf("want %v, got %v", ErrChecksumNotFound, err)
+		}
+		if entries, _ := os.ReadDir(m.CacheDir); len(entries) != 0 {
+			t.Errorf("want nothing installed, got %d entries", len(entries))
+		}
+	})
+	t.Run("unsupported firefox", func(t *testing.T) {
+		m := newTestManager(ts, "linux", "amd64")
+		m.CacheDir = t.TempDir()
+		if _, err := m.GeckoDriverFor(ctx, "60.0"); err == nil {
+			t.Errorf("expected error, but got nil")
+		}
+	})
+}
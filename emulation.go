@@ -0,0 +1,225 @@
+package navigator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ikawaha/navigator/cdp"
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+// ColorScheme is the value of the prefers-color-scheme media feature.
+type ColorScheme string
+
+const (
+	// ColorSchemeLight prefers the light color scheme.
+	ColorSchemeLight ColorScheme = "light"
+	// ColorSchemeDark prefers the dark color scheme.
+	ColorSchemeDark ColorScheme = "dark"
+)
+
+// MediaType is the CSS media type.
+type MediaType string
+
+const (
+	// MediaScreen is the screen media type.
+	MediaScreen MediaType = "screen"
+	// MediaPrint is the print media type.
+	MediaPrint MediaType = "print"
+)
+
+// Geolocation is the position reported by the Geolocation API.
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	// Accuracy is the accuracy of the position in meters.
+	Accuracy float64
+}
+
+// Emulation represents the environment emulated by the browser.
+// Zero values leave the corresponding settings as they are.
+//
+// Chromium based browsers emulate all settings on a running page.
+// Firefox emulates the settings when the browser is launched only, see
+// the Emulated Option, and does not support MediaType.
+type Emulation struct {
+	// Geolocation is the position reported by the Geolocation API.
+	Geolocation *Geolocation
+	// Timezone is the IANA timezone ID (ex. "Asia/Tokyo").
+	Timezone string
+	// Locale is the locale (ex. "ja-JP").
+	Locale string
+	// ColorScheme is the value of the prefers-color-scheme media feature.
+	ColorScheme ColorScheme
+	// ReducedMotion emulates the prefers-reduced-motion: reduce media feature.
+	ReducedMotion bool
+	// MediaType is the CSS media type.
+	MediaType MediaType
+}
+
+func (e Emulation) validate() error {
+	switch e.ColorScheme {
+	case "", ColorSchemeLight, ColorSchemeDark:
+	default:
+		return fmt.Errorf("invalid color scheme: %q", e.ColorScheme)
+	}
+	switch e.MediaType {
+	case "", MediaScreen, MediaPrint:
+	default:
+		return fmt.Errorf("invalid media type: %q", e.MediaType)
+	}
+	if g := e.Geolocation; g != nil {
+		if g.Latitude < -90 || g.Latitude > 90 {
+			return fmt.Errorf("invalid latitude: %v", g.Latitude)
+		}
+		if g.Longitude < -180 || g.Longitude > 180 {
+			return fmt.Errorf("invalid longitude: %v", g.Longitude)
+		}
+		if g.Accuracy < 0 {
+			return fmt.Errorf("invalid accuracy: %v", g.Accuracy)
+		}
+	}
+	return nil
+}
+
+// Emulate emulates the environment on the page. It is supported by Chromium
+// based browsers only. The emulation lasts until ResetEmulation or Reset is
+// called.
+func (p *Page) Emulate(e Emulation) error {
+	return p.EmulateWithContext(context.Background(), e)
+}
+
+// EmulateWithContext emulates the environment on the page. It is supported by
+// Chromium based browsers only. The emulation lasts until ResetEmulation or
+// Reset is called.
+func (p *Page) EmulateWithContext(ctx context.Context, e Emulation) error {
+	if err := e.validate(); err != nil {
+		return fmt.Errorf("failed to emulate: %w", err)
+	}
+	if !p.session.IsChromium() {
+		return fmt.Errorf("failed to emulate: %w: %q does not support emulation on a running page, use the Emulated Option instead", session.ErrUnsupported, p.session.BrowserName())
+	}
+	if err := emulateCDP(ctx, p.session, e); err != nil {
+		return fmt.Errorf("failed to emulate: %w", err)
+	}
+	p.mu.Lock()
+	p.emulated = true
+	p.mu.Unlock()
+	return nil
+}
+
+func emulateCDP(ctx context.Context, s cdp.Executor, e Emulation) error {
+	if g := e.Geolocation; g != nil {
+		if err := cdp.GrantPermissions(ctx, s, "", "geolocation"); err != nil {
+			return err
+		}
+		if err := cdp.SetGeolocationOverride(ctx, s, g.Latitude, g.Longitude, g.Accuracy); err != nil {
+			return err
+		}
+	}
+	if e.Timezone != "" {
+		if err := cdp.SetTimezoneOverride(ctx, s, e.Timezone); err != nil {
+			return err
+		}
+	}
+	if e.Locale != "" {
+		if err := cdp.SetLocaleOverride(ctx, s, strings.ReplaceAll(e.Locale, "-", "_")); err != nil {
+			return err
+		}
+	}
+	var features []cdp.MediaFeature
+	if e.ColorScheme != "" {
+		features = append(features, cdp.MediaFeature{Name: "prefers-color-scheme", Value: string(e.ColorScheme)})
+	}
+	if e.ReducedMotion {
+		features = append(features, cdp.MediaFeature{Name: "prefers-reduced-motion", Value: "reduce"})
+	}
+	if e.MediaType != "" || len(features) > 0 {
+		if err := cdp.SetEmulatedMedia(ctx, s, string(e.MediaType), features); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResetEmulation stops emulating the environment set by Emulate. The
+// environment of the Emulated Option, which the page was created with, is
+// emulated again.
+func (p *Page) ResetEmulation() error {
+	return p.ResetEmulationWithContext(context.Background())
+}
+
+// ResetEmulationWithContext stops emulating the environment set by Emulate.
+// The environment of the Emulated Option, which the page was created with, is
+// emulated again.
+func (p *Page) ResetEmulationWithContext(ctx context.Context) error {
+	p.mu.Lock()
+	emulated, base := p.emulated, p.baseEmulation
+	p.mu.Unlock()
+	if !emulated {
+		return nil
+	}
+	var errs []error
+	for _, reset := range []func(context.Context, cdp.Executor) error{
+		cdp.ClearGeolocationOverride,
+		cdp.ResetPermissions,
+		func(ctx context.Context, e cdp.Executor) error {
+			return cdp.SetTimezoneOverride(ctx, e, "")
+		},
+		func(ctx context.Context, e cdp.Executor) error {
+			return cdp.SetLocaleOverride(ctx, e, "")
+		},
+		func(ctx context.Context, e cdp.Executor) error {
+			return cdp.SetEmulatedMedia(ctx, e, "", nil)
+		},
+	} {
+		errs = append(errs, reset(ctx, p.session))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to reset emulation: %w", err)
+	}
+	if base != nil {
+		if err := emulateCDP(ctx, p.session, *base); err != nil {
+			return fmt.Errorf("failed to reset emulation: %w", err)
+		}
+	}
+	p.mu.Lock()
+	p.emulated = false
+	p.mu.Unlock()
+	return nil
+}
+
+// firefoxPrefs returns the Firefox preferences and environment variables
+// which emulate the environment on launch.
+func (e Emulation) firefoxPrefs() (prefs map[string]any, env map[string]string) {
+	prefs = map[string]any{}
+	env = map[string]string{}
+	if g := e.Geolocation; g != nil {
+		prefs["geo.provider.network.url"] = fmt.Sprintf(`data:application/json,{"location":{"lat":%v,"lng":%v},"accuracy":%v}`, g.Latitude, g.Longitude, g.Accuracy)
+		prefs["geo.prompt.testing"] = true
+		prefs["geo.prompt.testing.allow"] = true
+		prefs["permissions.default.geo"] = 1
+	}
+	if e.Timezone != "" {
+		env["TZ"] = e.Timezone
+	}
+	if e.Locale != "" {
+		prefs["intl.locale.requested"] = e.Locale
+		prefs["intl.accept_languages"] = e.Locale
+		prefs["javascript.use_us_english_locale"] = false
+	}
+	switch e.ColorScheme {
+	case ColorSchemeDark:
+		prefs["layout.css.prefers-color-scheme.content-override"] = 0
+		prefs["ui.systemUsesDarkTheme"] = 1
+	case ColorSchemeLight:
+		prefs["layout.css.prefers-color-scheme.content-override"] = 1
+		prefs["ui.systemUsesDarkTheme"] = 0
+	}
+	if e.ReducedMotion {
+		prefs["ui.prefersReducedMotion"] = 1
+	}
+	return prefs, env
+}
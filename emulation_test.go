@@ -0,0 +1,279 @@
+package navigator
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+	"github.com/ikawaha/navigator/webdriver/webdrivertest"
+)
+
+func TestEmulation_validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		emulation Emulation
+		wantErr   bool
+	}{
+		{name: "empty", emulation: Emulation{}},
+		{name: "valid", emulation: Emulation{
+			Geolocation: &Geolocation{Latitude: 35.68, Longitude: 139.76, Accuracy: 10},
+			ColorScheme: ColorSchemeDark,
+			MediaType:   MediaPrint,
+		}},
+		{name: "invalid color scheme", emulation: Emulation{ColorScheme: "blue"}, wantErr: true},
+		{name: "invalid media type", emulation: Emulation{MediaType: "tv"}, wantErr: true},
+		{name: "invalid latitude", emulation: Emulation{Geolocation: &Geolocation{Latitude: 91}}, wantErr: true},
+		{name: "invalid longitude", emulation: Emulation{Geolocation: &Geolocation{Longitude: -181}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.emulation.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEmulation_firefoxPrefs(t *testing.T) {
+	tests := []struct {
+		name      string
+		emulation Emulation
+		wantPrefs map[string]any
+		wantEnv   map[string]string
+	}{
+		{
+			name:      "empty",
+			emulation: Emulation{},
+			wantPrefs: map[string]any{},
+			wantEnv:   map[string]string{},
+		},
+		{
+			name:      "geolocation",
+			emulation: Emulation{Geolocation: &Geolocation{Latitude: 35.68, Longitude: 139.76, Accuracy: 10}},
+			wantPrefs: map[string]any{
+				"geo.provider.network.url": `data:application/json,{"location":{"lat":35.68,"lng":139.76},"accuracy":10}`,
+				"geo.prompt.testing":       true,
+				"geo.prompt.testing.allow": true,
+				"permissions.default.geo":  1,
+			},
+			wantEnv: map[string]string{},
+		},
+		{
+			name:      "timezone and locale",
+			emulation: Emulation{Timezone: "Asia/Tokyo", Locale: "ja-JP"},
+			wantPrefs: map[string]any{
+				"intl.locale.requested":            "ja-JP",
+				"intl.accept_languages":            "ja-JP",
+				"javascript.use_us_english_locale": false,
+			},
+			wantEnv: map[string]string{"TZ": "Asia/Tokyo"},
+		},
+		{
+			name:      "light color scheme and reduced motion",
+			emulation: Emulation{ColorScheme: ColorSchemeLight, ReducedMotion: true},
+			wantPrefs: map[string]any{
+				"layout.css.prefers-color-scheme.content-override": 1,
+				"ui.systemUsesDarkTheme":                           0,
+				"ui.prefersReducedMotion":                          1,
+			},
+			wantEnv: map[string]string{},
+		},
+		{
+			name:      "media type is not supported",
+			emulation: Emulation{MediaType: MediaPrint},
+			wantPrefs: map[string]any{},
+			wantEnv:   map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs, env := tt.emulation.firefoxPrefs()
+			if !reflect.DeepEqual(prefs, tt.wantPrefs) {
+				t.Errorf("want %+v, got %+v", tt.wantPrefs, prefs)
+			}
+			if !reflect.DeepEqual(env, tt.wantEnv) {
+				t.Errorf("want %+v, got %+v", tt.wantEnv, env)
+			}
+		})
+	}
+}
+
+// resetCommands are the CDP commands which reset the emulation.
+var resetCommands = []webdrivertest.CDPCommand{
+	{Cmd: "Emulation.clearGeolocationOverride", Params: map[string]any{}},
+	{Cmd: "Browser.resetPermissions", Params: map[string]any{}},
+	{Cmd: "Emulation.setTimezoneOverride", Params: map[string]any{"timezoneId": ""}},
+	{Cmd: "Emulation.setLocaleOverride", Params: map[string]any{}},
+	{Cmd: "Emulation.setEmulatedMedia", Params: map[string]any{"media": "", "features": []any{}}},
+}
+
+// cdpCommands returns the CDP commands sent on the session of the page,
+// without the session ID.
+func cdpCommands(server *webdrivertest.Server, page *Page) []webdrivertest.CDPCommand {
+	var ret []webdrivertest.CDPCommand
+	for _, c := range server.CDPCommands() {
+		if c.SessionID == page.Session().ID() {
+			c.SessionID = ""
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+func TestPage_Emulate(t *testing.T) {
+	driver, server := startTestDriver(t)
+	tests := []struct {
+		name      string
+		emulation Emulation
+		want      []webdrivertest.CDPCommand
+	}{
+		{
+			name:      "geolocation",
+			emulation: Emulation{Geolocation: &Geolocation{Latitude: 35.68, Longitude: 139.76, Accuracy: 10}},
+			want: []webdrivertest.CDPCommand{
+				{Cmd: "Browser.grantPermissions", Params: map[string]any{"permissions": []any{"geolocation"}}},
+				{Cmd: "Emulation.setGeolocationOverride", Params: map[string]any{"latitude": 35.68, "longitude": 139.76, "accuracy": 10.0}},
+			},
+		},
+		{
+			name:      "timezone",
+			emulation: Emulation{Timezone: "Asia/Tokyo"},
+			want: []webdrivertest.CDPCommand{
+				{Cmd: "Emulation.setTimezoneOverride", Params: map[string]any{"timezoneId": "Asia/Tokyo"}},
+			},
+		},
+		{
+			name:      "locale",
+			emulation: Emulation{Locale: "ja-JP"},
+			want: []webdrivertest.CDPCommand{
+				{Cmd: "Emulation.setLocaleOverride", Params: map[string]any{"locale": "ja_JP"}},
+			},
+		},
+		{
+			name:      "media",
+			emulation: Emulation{ColorScheme: ColorSchemeDark, ReducedMotion: true, MediaType: MediaPrint},
+			want: []webdrivertest.CDPCommand{
+				{Cmd: "Emulation.setEmulatedMedia", Params: map[string]any{
+					"media": "print",
+					"features": []any{
+						map[string]any{"name": "prefers-color-scheme", "value": "dark"},
+						map[string]any{"name": "prefers-reduced-motion", "value": "reduce"},
+					},
+				}},
+			},
+		},
+		{
+			name:      "empty",
+			emulation: Emulation{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := driver.NewPage(Browser("chrome"))
+			if err != nil {
+				t.Fatalf("NewPage() failed: unexpected error %v", err)
+			}
+			defer page.Destroy()
+			if err := page.Emulate(tt.emulation); err != nil {
+				t.Fatalf("Emulate() failed: unexpected error %v", err)
+			}
+			if got := cdpCommands(server, page); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+	t.Run("firefox", func(t *testing.T) {
+		page, err := driver.NewPage(Browser("firefox"))
+		if err != nil {
+			t.Fatalf("NewPage() failed: unexpected error %v", err)
+		}
+		defer page.Destroy()
+		if err := page.Emulate(Emulation{Timezone: "Asia/Tokyo"}); !errors.Is(err, session.ErrUnsupported) {
+			t.Errorf("want %v, got %v", session.ErrUnsupported, err)
+		}
+		if got := cdpCommands(server, page); len(got) != 0 {
+			t.Errorf("want no commands, got %+v", got)
+		}
+	})
+}
+
+func TestPage_ResetEmulation(t *testing.T) {
+	driver, server := startTestDriver(t)
+	tests := []struct {
+		name    string
+		options []Option
+		want    []webdrivertest.CDPCommand
+	}{
+		{
+			name:    "without Emulated",
+			options: []Option{Browser("chrome")},
+			want:    resetCommands,
+		},
+		{
+			name:    "with Emulated",
+			options: []Option{Browser("chrome"), Emulated(Emulation{Timezone: "Asia/Tokyo"})},
+			// the environment of the Emulated Option is emulated again.
+			want: append(append([]webdrivertest.CDPCommand{}, resetCommands...),
+				webdrivertest.CDPCommand{Cmd: "Emulation.setTimezoneOverride", Params: map[string]any{"timezoneId": "Asia/Tokyo"}},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := driver.NewPage(tt.options...)
+			if err != nil {
+				t.Fatalf("NewPage() failed: unexpected error %v", err)
+			}
+			defer page.Destroy()
+			if err := page.Emulate(Emulation{Locale: "ja-JP"}); err != nil {
+				t.Fatalf("Emulate() failed: unexpected error %v", err)
+			}
+			emulated := len(cdpCommands(server, page))
+			if err := page.ResetEmulation(); err != nil {
+				t.Fatalf("ResetEmulation() failed: unexpected error %v", err)
+			}
+			if got := cdpCommands(server, page)[emulated:]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+
+			// the emulation has already been reset.
+			reset := len(cdpCommands(server, page))
+			if err := page.ResetEmulation(); err != nil {
+				t.Fatalf("ResetEmulation() failed: unexpected error %v", err)
+			}
+			if got := cdpCommands(server, page)[reset:]; len(got) != 0 {
+				t.Errorf("want no commands, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestPage_Reset(t *testing.T) {
+	driver, server := startTestDriver(t)
+	page, err := driver.NewPage(Browser("chrome"))
+	if err != nil {
+		t.Fatalf("NewPage() failed: unexpected error %v", err)
+	}
+	defer page.Destroy()
+	if err := page.Navigate("http://example.com"); err != nil {
+		t.Fatalf("Navigate() failed: unexpected error %v", err)
+	}
+	if err := page.Emulate(Emulation{Timezone: "Europe/Paris"}); err != nil {
+		t.Fatalf("Emulate() failed: unexpected error %v", err)
+	}
+	emulated := len(cdpCommands(server, page))
+	if err := page.Reset(); err != nil {
+		t.Fatalf("Reset() failed: unexpected error %v", err)
+	}
+	if got := cdpCommands(server, page)[emulated:]; !reflect.DeepEqual(got, resetCommands) {
+		t.Errorf("want %+v, got %+v", resetCommands, got)
+	}
+	url, err := page.URL()
+	if err != nil {
+		t.Fatalf("URL() failed: unexpected error %v", err)
+	}
+	if url != aboutBlankURL {
+		t.Errorf("want %q, got %q", aboutBlankURL, url)
+	}
+}
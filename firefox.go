@@ -0,0 +1,205 @@
+package navigator
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FirefoxConfig is the configuration of Firefox passed to geckodriver under
+// the moz:firefoxOptions capability.
+//
+// See: https://developer.mozilla.org/en-US/docs/Web/WebDriver/Capabilities/firefoxOptions
+type FirefoxConfig struct {
+	// Binary is the path to the Firefox executable.
+	Binary string
+	// Args are the command-line arguments of Firefox (ex. "-headless").
+	Args []string
+	// Prefs are the preferences of the profile.
+	Prefs map[string]any
+	// LogLevel is the log level of geckodriver and Firefox
+	// ("trace", "debug", "config", "info", "warn", "error" or "fatal").
+	LogLevel string
+	// Profile is the path to the profile directory, which is zipped and
+	// passed to geckodriver.
+	Profile string
+	// Env are the environment variables of Firefox.
+	Env map[string]string
+}
+
+func (c *FirefoxConfig) validate() error {
+	switch c.LogLevel {
+	case "", "trace", "debug", "config", "info", "warn", "error", "fatal":
+	default:
+		return fmt.Errorf("invalid log level: %q", c.LogLevel)
+	}
+	if c.Binary != "" {
+		if _, err := os.Stat(c.Binary); err != nil {
+			return fmt.Errorf("invalid binary: %w", err)
+		}
+	}
+	if c.Profile != "" {
+		info, err := os.Stat(c.Profile)
+		if err != nil {
+			return fmt.Errorf("invalid profile: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("invalid profile: %s is not a directory", c.Profile)
+		}
+	}
+	return nil
+}
+
+// options returns the configuration as the value of moz:firefoxOptions.
+func (c *FirefoxConfig) options() (map[string]any, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	opts := map[string]any{}
+	if c.Binary != "" {
+		opts["binary"] = c.Binary
+	}
+	if len(c.Args) > 0 {
+		opts["args"] = c.Args
+	}
+	if c.Prefs != nil {
+		opts["prefs"] = c.Prefs
+	}
+	if c.LogLevel != "" {
+		opts["log"] = map[string]any{"level": c.LogLevel}
+	}
+	if c.Profile != "" {
+		profile, err := zipDirBase64(c.Profile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile: %w", err)
+		}
+		opts["profile"] = profile
+	}
+	if c.Env != nil {
+		opts["env"] = c.Env
+	}
+	return opts, nil
+}
+
+// profileLockFiles are the files excluded from a zipped profile.
+var profileLockFiles = map[string]bool{
+	"parent.lock": true,
+	"lock":        true,
+	".parentlock": true,
+}
+
+// zipDirBase64 zips the directory and returns it base64 encoded.
+func zipDirBase64(dir string) (string, error) {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || profileLockFiles[d.Name()] {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		dst, err := w.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(dst, src)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes()), nil
+}
+
+// FirefoxContext is the context of the commands sent to Firefox.
+type FirefoxContext string
+
+const (
+	// ContextContent sends the commands to the web content.
+	ContextContent FirefoxContext = "content"
+	// ContextChrome sends the commands to the browser UI (privileged).
+	ContextChrome FirefoxContext = "chrome"
+)
+
+// InstallAddon installs the Firefox add-on at the path, which is either an XPI
+// file or an unpacked add-on directory, and returns the ID of the add-on.
+// A temporary add-on is removed when the browser is closed, and may be unsigned.
+func (p *Page) InstallAddon(path string, temporary bool) (string, error) {
+	return p.InstallAddonWithContext(context.Background(), path, temporary)
+}
+
+// InstallAddonWithContext installs the Firefox add-on at the path, which is
+// either an XPI file or an unpacked add-on directory, and returns the ID of
+// the add-on. A temporary add-on is removed when the browser is closed, and
+// may be unsigned.
+func (p *Page) InstallAddonWithContext(ctx context.Context, path string, temporary bool) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to install add-on: %w", err)
+	}
+	var addon string
+	if info.IsDir() {
+		addon, err = zipDirBase64(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to install add-on: %w", err)
+		}
+	} else {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to install add-on: %w", err)
+		}
+		addon = base64.StdEncoding.EncodeToString(b)
+	}
+	id, err := p.session.InstallAddon(ctx, addon, temporary)
+	if err != nil {
+		return "", fmt.Errorf("failed to install add-on: %w", err)
+	}
+	return id, nil
+}
+
+// UninstallAddon uninstalls the Firefox add-on.
+func (p *Page) UninstallAddon(id string) error {
+	return p.UninstallAddonWithContext(context.Background(), id)
+}
+
+// UninstallAddonWithContext uninstalls the Firefox add-on.
+func (p *Page) UninstallAddonWithContext(ctx context.Context, id string) error {
+	if err := p.session.UninstallAddon(ctx, id); err != nil {
+		return fmt.Errorf("failed to uninstall add-on %s: %w", id, err)
+	}
+	return nil
+}
+
+// SetContext switches the context of the commands sent to Firefox. For
+// instance, RunScript in ContextChrome runs the script with the privileges of
+// the browser UI.
+func (p *Page) SetContext(c FirefoxContext) error {
+	return p.SetContextWithContext(context.Background(), c)
+}
+
+// SetContextWithContext switches the context of the commands sent to Firefox.
+func (p *Page) SetContextWithContext(ctx context.Context, c FirefoxContext) error {
+	if err := p.session.SetContext(ctx, string(c)); err != nil {
+		return fmt.Errorf("failed to set context: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package navigator
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func Test_zipDirBase64(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"prefs.js":               `user_pref("browser.startup.homepage", "about:blank");`,
+		"parent.lock":            "",
+		"extensions/addon.xpi":   "xpi",
+		"storage/default/a.json": "{}",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("os.MkdirAll() failed: unexpected error %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("os.WriteFile() failed: unexpected error %v", err)
+		}
+	}
+	encoded, err := zipDirBase64(dir)
+	if err != nil {
+		t.Fatalf("zipDirBase64() failed: unexpected error %v", err)
+	}
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("base64.DecodeString() failed: unexpected error %v", err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() failed: unexpected error %v", err)
+	}
+	var got []string
+	for _, f := range r.File {
+		got = append(got, f.Name)
+	}
+	sort.Strings(got)
+	if want := []string{"extensions/addon.xpi", "prefs.js", "storage/default/a.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestFirefoxConfig_options(t *testing.T) {
+	c := newConfig([]Option{
+		Firefox(FirefoxConfig{
+			Args:     []string{"-private"},
+			Prefs:    map[string]any{"intl.accept_languages": "en", "browser.tabs.warnOnClose": false},
+			LogLevel: "trace",
+			Env:      map[string]string{"MOZ_LOG": "all:5"},
+		}),
+		Headless,
+		Emulated(Emulation{Locale: "ja-JP", Timezone: "Asia/Tokyo"}),
+	})
+	got, err := c.firefoxCapability()
+	if err != nil {
+		t.Fatalf("firefoxCapability() failed: unexpected error %v", err)
+	}
+	want := map[string]any{
+		"args": []string{"-private", "-headless"},
+		"prefs": map[string]any{
+			"browser.tabs.warnOnClose":         false,
+			"intl.accept_languages":            "ja-JP",
+			"intl.locale.requested":            "ja-JP",
+			"javascript.use_us_english_locale": false,
+		},
+		"log": map[string]any{"level": "trace"},
+		"env": map[string]string{"MOZ_LOG": "all:5", "TZ": "Asia/Tokyo"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	for _, cfg := range []FirefoxConfig{
+		{LogLevel: "verbose"},
+		{Profile: "testdata/not_found"},
+		{Profile: "testdata/hello.html"},
+	} {
+		c := newConfig([]Option{Firefox(cfg)})
+		if _, err := c.firefoxCapability(); err == nil {
+			t.Errorf("%+v: expected error, but nil", cfg)
+		}
+	}
+}
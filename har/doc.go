@@ -0,0 +1,3 @@
+// Package har converts the DevTools network events reported in the Chrome
+// performance log into HTTP Archive (HAR 1.2) documents.
+package har
@@ -0,0 +1,87 @@
+package har
+
+import (
+	"encoding/json"
+)
+
+// performanceMessage is a message of the Chrome performance log.
+type performanceMessage struct {
+	Message struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	} `json:"message"`
+	Webview string `json:"webview"`
+}
+
+type request struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	Headers     map[string]string `json:"headers"`
+	PostData    string            `json:"postData"`
+	HasPostData bool              `json:"hasPostData"`
+}
+
+type resourceTiming struct {
+	RequestTime       float64 `json:"requestTime"`
+	DNSStart          float64 `json:"dnsStart"`
+	DNSEnd            float64 `json:"dnsEnd"`
+	ConnectStart      float64 `json:"connectStart"`
+	ConnectEnd        float64 `json:"connectEnd"`
+	SSLStart          float64 `json:"sslStart"`
+	SSLEnd            float64 `json:"sslEnd"`
+	SendStart         float64 `json:"sendStart"`
+	SendEnd           float64 `json:"sendEnd"`
+	ReceiveHeadersEnd float64 `json:"receiveHeadersEnd"`
+}
+
+type response struct {
+	URL               string            `json:"url"`
+	Status            int               `json:"status"`
+	StatusText        string            `json:"statusText"`
+	Headers           map[string]string `json:"headers"`
+	MimeType          string            `json:"mimeType"`
+	Protocol          string            `json:"protocol"`
+	RemoteIPAddress   string            `json:"remoteIPAddress"`
+	EncodedDataLength float64           `json:"encodedDataLength"`
+	FromDiskCache     bool              `json:"fromDiskCache"`
+	Timing            *resourceTiming   `json:"timing"`
+}
+
+// Network.requestWillBeSent
+type requestWillBeSent struct {
+	RequestID        string    `json:"requestId"`
+	Request          request   `json:"request"`
+	Timestamp        float64   `json:"timestamp"`
+	WallTime         float64   `json:"wallTime"`
+	Type             string    `json:"type"`
+	RedirectResponse *response `json:"redirectResponse"`
+}
+
+// Network.responseReceived
+type responseReceived struct {
+	RequestID string   `json:"requestId"`
+	Timestamp float64  `json:"timestamp"`
+	Type      string   `json:"type"`
+	Response  response `json:"response"`
+}
+
+// Network.dataReceived
+type dataReceived struct {
+	RequestID  string `json:"requestId"`
+	DataLength int64  `json:"dataLength"`
+}
+
+// Network.loadingFinished
+type loadingFinished struct {
+	RequestID         string  `json:"requestId"`
+	Timestamp         float64 `json:"timestamp"`
+	EncodedDataLength float64 `json:"encodedDataLength"`
+}
+
+// Network.loadingFailed
+type loadingFailed struct {
+	RequestID string  `json:"requestId"`
+	Timestamp float64 `json:"timestamp"`
+	ErrorText string  `json:"errorText"`
+	Canceled  bool    `json:"canceled"`
+}
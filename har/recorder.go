@@ -0,0 +1,342 @@
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Exchange is a request observed in the performance log and, if it has
+// been received, its response.
+type Exchange struct {
+	// RequestID is the DevTools identifier of the request. Redirected
+	// requests share the identifier of the original request.
+	RequestID string
+	// ResourceType is the DevTools resource type (ex. "Document", "XHR").
+	ResourceType string
+	// Started is the wall-clock time the request was issued.
+	Started time.Time
+
+	Method         string
+	URL            string
+	RequestHeaders map[string]string
+	PostData       string
+
+	Status            int
+	StatusText        string
+	MimeType          string
+	Protocol          string
+	ResponseHeaders   map[string]string
+	RemoteIPAddress   string
+	RedirectURL       string
+	FromDiskCache     bool
+	DataLength        int64
+	EncodedDataLength int64
+
+	// Finished reports whether the response body was completely loaded.
+	Finished bool
+	// ErrorText is the reason of the failure if the request failed.
+	ErrorText string
+
+	startTimestamp float64
+	endTimestamp   float64
+	timing         *resourceTiming
+}
+
+// Responded reports whether a response was received for the request.
+func (e *Exchange) Responded() bool {
+	return e.Status != 0
+}
+
+// Failed reports whether the request failed to load.
+func (e *Exchange) Failed() bool {
+	return e.ErrorText != ""
+}
+
+// Done reports whether the exchange will not change any more.
+func (e *Exchange) Done() bool {
+	return e.Finished || e.Failed() || e.RedirectURL != ""
+}
+
+func (e *Exchange) setResponse(r response) {
+	e.Status = r.Status
+	e.StatusText = r.StatusText
+	e.MimeType = r.MimeType
+	e.Protocol = r.Protocol
+	e.ResponseHeaders = r.Headers
+	e.RemoteIPAddress = r.RemoteIPAddress
+	e.FromDiskCache = r.FromDiskCache
+	e.EncodedDataLength = int64(r.EncodedDataLength)
+	e.timing = r.Timing
+}
+
+// Timings returns the timings of the exchange in milliseconds.
+func (e *Exchange) Timings() Timings {
+	t := Timings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: 0, Receive: 0, SSL: -1}
+	if e.timing == nil {
+		if e.endTimestamp > e.startTimestamp {
+			t.Receive = (e.endTimestamp - e.startTimestamp) * 1000
+		}
+		return t
+	}
+	rt := e.timing
+	for _, v := range []float64{rt.DNSStart, rt.ConnectStart, rt.SendStart} {
+		if v >= 0 {
+			t.Blocked = v
+			break
+		}
+	}
+	if rt.DNSStart >= 0 {
+		t.DNS = rt.DNSEnd - rt.DNSStart
+	}
+	if rt.ConnectStart >= 0 {
+		t.Connect = rt.ConnectEnd - rt.ConnectStart
+	}
+	if rt.SSLStart >= 0 {
+		t.SSL = rt.SSLEnd - rt.SSLStart
+	}
+	t.Send = rt.SendEnd - rt.SendStart
+	t.Wait = rt.ReceiveHeadersEnd - rt.SendEnd
+	if e.endTimestamp > 0 {
+		if receive := (e.endTimestamp-rt.RequestTime)*1000 - rt.ReceiveHeadersEnd; receive > 0 {
+			t.Receive = receive
+		}
+	}
+	return t
+}
+
+// Entry returns the exchange as a HAR entry.
+func (e *Exchange) Entry() Entry {
+	timings := e.Timings()
+	httpVersion := httpVersion(e.Protocol)
+	req := Request{
+		Method:      e.Method,
+		URL:         e.URL,
+		HTTPVersion: httpVersion,
+		Cookies:     []Cookie{},
+		Headers:     toNameValues(e.RequestHeaders),
+		QueryString: queryString(e.URL),
+		HeadersSize: -1,
+		BodySize:    int64(len(e.PostData)),
+	}
+	if e.PostData != "" {
+		req.PostData = &PostData{
+			MimeType: headerValue(e.RequestHeaders, "Content-Type"),
+			Text:     e.PostData,
+		}
+	}
+	resp := Response{
+		Status:      e.Status,
+		StatusText:  e.StatusText,
+		HTTPVersion: httpVersion,
+		Cookies:     []Cookie{},
+		Headers:     toNameValues(e.ResponseHeaders),
+		Content: Content{
+			Size:     e.DataLength,
+			MimeType: e.MimeType,
+		},
+		RedirectURL: e.RedirectURL,
+		HeadersSize: -1,
+		BodySize:    e.EncodedDataLength,
+	}
+	if !e.Responded() {
+		resp.BodySize = -1
+	}
+	return Entry{
+		StartedDateTime: e.Started,
+		Time:            timings.Total(),
+		Request:         req,
+		Response:        resp,
+		Cache:           Cache{},
+		Timings:         timings,
+		ServerIPAddress: e.RemoteIPAddress,
+		Comment:         e.ErrorText,
+	}
+}
+
+func httpVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "":
+		return ""
+	case "h2":
+		return "HTTP/2.0"
+	case "h3":
+		return "HTTP/3.0"
+	}
+	return strings.ToUpper(protocol)
+}
+
+func toNameValues(m map[string]string) []NameValue {
+	ret := make([]NameValue, 0, len(m))
+	for k, v := range m {
+		ret = append(ret, NameValue{Name: k, Value: v})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+func queryString(rawURL string) []NameValue {
+	ret := []NameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ret
+	}
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			ret = append(ret, NameValue{Name: k, Value: v})
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+var creator = Creator{
+	Name:    "github.com/ikawaha/navigator",
+	Version: "devel",
+}
+
+// Recorder collects exchanges from the messages of the performance log.
+type Recorder struct {
+	exchanges []*Exchange
+	inflight  map[string]*Exchange
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		inflight: map[string]*Exchange{},
+	}
+}
+
+// Add adds a message of the performance log to the recorder.
+// Messages other than network events are ignored.
+func (r *Recorder) Add(message string) error {
+	var m performanceMessage
+	if err := json.Unmarshal([]byte(message), &m); err != nil {
+		return fmt.Errorf("invalid performance log message: %w", err)
+	}
+	params := m.Message.Params
+	switch m.Message.Method {
+	case "Network.requestWillBeSent":
+		var ev requestWillBeSent
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return fmt.Errorf("invalid %s event: %w", m.Message.Method, err)
+		}
+		r.requestWillBeSent(ev)
+	case "Network.responseReceived":
+		var ev responseReceived
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return fmt.Errorf("invalid %s event: %w", m.Message.Method, err)
+		}
+		if e, ok := r.inflight[ev.RequestID]; ok {
+			e.setResponse(ev.Response)
+		}
+	case "Network.dataReceived":
+		var ev dataReceived
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return fmt.Errorf("invalid %s event: %w", m.Message.Method, err)
+		}
+		if e, ok := r.inflight[ev.RequestID]; ok {
+			e.DataLength += ev.DataLength
+		}
+	case "Network.loadingFinished":
+		var ev loadingFinished
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return fmt.Errorf("invalid %s event: %w", m.Message.Method, err)
+		}
+		if e, ok := r.inflight[ev.RequestID]; ok {
+			e.Finished = true
+			e.endTimestamp = ev.Timestamp
+			e.EncodedDataLength = int64(ev.EncodedDataLength)
+			delete(r.inflight, ev.RequestID)
+		}
+	case "Network.loadingFailed":
+		var ev loadingFailed
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return fmt.Errorf("invalid %s event: %w", m.Message.Method, err)
+		}
+		if e, ok := r.inflight[ev.RequestID]; ok {
+			e.ErrorText = ev.ErrorText
+			e.endTimestamp = ev.Timestamp
+			delete(r.inflight, ev.RequestID)
+		}
+	}
+	return nil
+}
+
+func (r *Recorder) requestWillBeSent(ev requestWillBeSent) {
+	if prev, ok := r.inflight[ev.RequestID]; ok && ev.RedirectResponse != nil {
+		prev.setResponse(*ev.RedirectResponse)
+		prev.RedirectURL = ev.Request.URL
+		prev.endTimestamp = ev.Timestamp
+	}
+	sec, frac := splitSeconds(ev.WallTime)
+	e := &Exchange{
+		RequestID:      ev.RequestID,
+		ResourceType:   ev.Type,
+		Started:        time.Unix(sec, frac),
+		Method:         ev.Request.Method,
+		URL:            ev.Request.URL,
+		RequestHeaders: ev.Request.Headers,
+		PostData:       ev.Request.PostData,
+		startTimestamp: ev.Timestamp,
+	}
+	r.exchanges = append(r.exchanges, e)
+	r.inflight[ev.RequestID] = e
+}
+
+func splitSeconds(s float64) (sec, nsec int64) {
+	sec = int64(s)
+	nsec = int64((s - float64(sec)) * float64(time.Second))
+	return sec, nsec
+}
+
+// Exchanges returns the recorded exchanges in the order they were issued.
+func (r *Recorder) Exchanges() []*Exchange {
+	ret := make([]*Exchange, len(r.exchanges))
+	copy(ret, r.exchanges)
+	return ret
+}
+
+// Match returns the recorded exchanges whose URL matches the pattern.
+func (r *Recorder) Match(pattern *regexp.Regexp) []*Exchange {
+	var ret []*Exchange
+	for _, e := range r.exchanges {
+		if pattern.MatchString(e.URL) {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// HAR returns the recorded exchanges as a HAR document.
+func (r *Recorder) HAR() *HAR {
+	entries := make([]Entry, 0, len(r.exchanges))
+	for _, e := range r.exchanges {
+		entries = append(entries, e.Entry())
+	}
+	return &HAR{
+		Log: Log{
+			Version: Version,
+			Creator: creator,
+			Entries: entries,
+		},
+	}
+}
@@ -0,0 +1,179 @@
+package har
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"testing"
+)
+
+func loadRecorder(t *testing.T) *Recorder {
+	t.Helper()
+	b, err := os.ReadFile("testdata/performance_log.json")
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: unexpected error %v", err)
+	}
+	var logs []struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(b, &logs); err != nil {
+		t.Fatalf("json.Unmarshal() failed: unexpected error %v", err)
+	}
+	r := NewRecorder()
+	for _, v := range logs {
+		if err := r.Add(v.Message); err != nil {
+			t.Fatalf("r.Add() failed: unexpected error %v", err)
+		}
+	}
+	return r
+}
+
+func TestRecorder_Exchanges(t *testing.T) {
+	r := loadRecorder(t)
+	got := r.Exchanges()
+	if want := 4; len(got) != want {
+		t.Fatalf("want %d exchanges, got %d", want, len(got))
+	}
+	tests := []struct {
+		name        string
+		exchange    *Exchange
+		url         string
+		status      int
+		redirectURL string
+		finished    bool
+		failed      bool
+	}{
+		{
+			name:     "document",
+			exchange: got[0],
+			url:      "http://127.0.0.1:8080/hello",
+			status:   200,
+			finished: true,
+		},
+		{
+			name:        "redirected request",
+			exchange:    got[1],
+			url:         "http://127.0.0.1:8080/api/items?page=2&sort=name",
+			status:      302,
+			redirectURL: "http://127.0.0.1:8080/api/v2/items?page=2&sort=name",
+		},
+		{
+			name:     "redirect target",
+			exchange: got[2],
+			url:      "http://127.0.0.1:8080/api/v2/items?page=2&sort=name",
+			status:   200,
+			finished: true,
+		},
+		{
+			name:     "failed request",
+			exchange: got[3],
+			url:      "https://tracker.example.com/pixel.gif",
+			failed:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.exchange
+			if e.URL != tt.url {
+				t.Errorf("want URL %q, got %q", tt.url, e.URL)
+			}
+			if e.Status != tt.status {
+				t.Errorf("want status %d, got %d", tt.status, e.Status)
+			}
+			if e.RedirectURL != tt.redirectURL {
+				t.Errorf("want redirect URL %q, got %q", tt.redirectURL, e.RedirectURL)
+			}
+			if e.Finished != tt.finished {
+				t.Errorf("want finished %v, got %v", tt.finished, e.Finished)
+			}
+			if e.Failed() != tt.failed {
+				t.Errorf("want failed %v, got %v", tt.failed, e.Failed())
+			}
+			if !e.Done() {
+				t.Errorf("want done, but not")
+			}
+		})
+	}
+}
+
+func TestRecorder_Match(t *testing.T) {
+	r := loadRecorder(t)
+	got := r.Match(regexp.MustCompile(`/api/`))
+	if want := 2; len(got) != want {
+		t.Fatalf("want %d exchanges, got %d", want, len(got))
+	}
+	if got := r.Match(regexp.MustCompile(`^never$`)); len(got) != 0 {
+		t.Errorf("want no exchanges, got %d", len(got))
+	}
+}
+
+func TestRecorder_HAR(t *testing.T) {
+	r := loadRecorder(t)
+	h := r.HAR()
+	if got, want := h.Log.Version, "1.2"; got != want {
+		t.Errorf("want version %q, got %q", want, got)
+	}
+	if got, want := len(h.Log.Entries), 4; got != want {
+		t.Fatalf("want %d entries, got %d", want, got)
+	}
+	doc := h.Log.Entries[0]
+	if got, want := doc.Timings, (Timings{Blocked: 0.5, DNS: 1, Connect: 2, Send: 0.5, Wait: 6, Receive: 2, SSL: -1}); !timingsEqual(got, want) {
+		t.Errorf("want timings %+v, got %+v", want, got)
+	}
+	if got, want := doc.Time, 12.0; !floatEqual(got, want) {
+		t.Errorf("want time %v, got %v", want, got)
+	}
+	if got, want := doc.Response.Content.Size, int64(120); got != want {
+		t.Errorf("want content size %d, got %d", want, got)
+	}
+	if got, want := doc.StartedDateTime.UnixMilli(), int64(1700000000250); got != want {
+		t.Errorf("want started %d, got %d", want, got)
+	}
+	post := h.Log.Entries[1]
+	if post.Request.PostData == nil {
+		t.Fatalf("want post data, got nil")
+	}
+	if got, want := post.Request.PostData.MimeType, "application/json"; got != want {
+		t.Errorf("want mime type %q, got %q", want, got)
+	}
+	if got, want := post.Request.QueryString, []NameValue{{"page", "2"}, {"sort", "name"}}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("want query string %+v, got %+v", want, got)
+	}
+	if got, want := h.Log.Entries[2].Request.HTTPVersion, "HTTP/2.0"; got != want {
+		t.Errorf("want HTTP version %q, got %q", want, got)
+	}
+	if got, want := h.Log.Entries[3].Comment, "net::ERR_NAME_NOT_RESOLVED"; got != want {
+		t.Errorf("want comment %q, got %q", want, got)
+	}
+	if _, err := json.Marshal(h); err != nil {
+		t.Errorf("json.Marshal() failed: unexpected error %v", err)
+	}
+}
+
+func TestRecorder_Add(t *testing.T) {
+	r := NewRecorder()
+	if err := r.Add("not json"); err == nil {
+		t.Errorf("expected error, but nil")
+	}
+	if err := r.Add(`{"message":{"method":"Page.loadEventFired","params":{}}}`); err != nil {
+		t.Errorf("r.Add() failed: unexpected error %v", err)
+	}
+	if got := len(r.Exchanges()); got != 0 {
+		t.Errorf("want no exchanges, got %d", got)
+	}
+}
+
+func floatEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-6 && d > -1e-6
+}
+
+func timingsEqual(a, b Timings) bool {
+	return floatEqual(a.Blocked, b.Blocked) &&
+		floatEqual(a.DNS, b.DNS) &&
+		floatEqual(a.Connect, b.Connect) &&
+		floatEqual(a.Send, b.Send) &&
+		floatEqual(a.Wait, b.Wait) &&
+		floatEqual(a.Receive, b.Receive) &&
+		floatEqual(a.SSL, b.SSL)
+}
@@ -0,0 +1,26 @@
+// Package browser provides the lookup of the installed browsers shared by
+// navigator and drivermgr.
+package browser
+
+import "regexp"
+
+// VersionPattern matches the version in the output of --version
+// (ex. "120.0.6099.109" of "Google Chrome 120.0.6099.109"). The first
+// submatch is the major version.
+var VersionPattern = regexp.MustCompile(`\b(\d+)\.\d+(?:\.\d+)*\b`)
+
+// ChromeCandidates returns the executable names or paths of Chrome on the OS.
+func ChromeCandidates(goos string) []string {
+	switch goos {
+	case "darwin":
+		return []string{
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+		}
+	case "windows":
+		// chrome.exe does not print its version to the console.
+		return nil
+	default:
+		return []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "chrome"}
+	}
+}
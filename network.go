@@ -0,0 +1,118 @@
+package navigator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/ikawaha/navigator/har"
+)
+
+// PerformanceLog is the log type of the Chrome performance log. The log has to
+// be enabled with the LoggingPrefs Option to record network traffic:
+//
+//	driver.NewPage(navigator.LoggingPrefs(navigator.PerformanceLog, "ALL"))
+const PerformanceLog = "performance"
+
+const requestsPollInterval = 100 * time.Millisecond
+
+// HAR returns the network traffic of the page recorded in the performance log
+// as a HAR 1.2 document.
+func (p *Page) HAR() (*har.HAR, error) {
+	return p.HARWithContext(context.Background())
+}
+
+// HARWithContext returns the network traffic of the page recorded in the
+// performance log as a HAR 1.2 document.
+func (p *Page) HARWithContext(ctx context.Context) (*har.HAR, error) {
+	r, err := p.recordNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.HAR(), nil
+}
+
+// Requests returns Requests that queries the network traffic of the page.
+func (p *Page) Requests() *Requests {
+	return &Requests{page: p}
+}
+
+func (p *Page) recordNetwork(ctx context.Context) (*har.Recorder, error) {
+	logs, err := p.ReadAllLogsWithContext(ctx, PerformanceLog)
+	if err != nil {
+		return nil, err
+	}
+	r := har.NewRecorder()
+	for _, v := range logs {
+		if err := r.Add(v.Message); err != nil {
+			return nil, fmt.Errorf("failed to parse performance log: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// Requests queries the network traffic of a Page recorded in the performance log.
+type Requests struct {
+	page *Page
+}
+
+// All returns all requests issued by the page.
+func (r *Requests) All() ([]*har.Exchange, error) {
+	return r.AllWithContext(context.Background())
+}
+
+// AllWithContext returns all requests issued by the page.
+func (r *Requests) AllWithContext(ctx context.Context) ([]*har.Exchange, error) {
+	rec, err := r.page.recordNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rec.Exchanges(), nil
+}
+
+// Match returns the requests whose URL matches the pattern.
+func (r *Requests) Match(pattern *regexp.Regexp) ([]*har.Exchange, error) {
+	return r.MatchWithContext(context.Background(), pattern)
+}
+
+// MatchWithContext returns the requests whose URL matches the pattern.
+func (r *Requests) MatchWithContext(ctx context.Context, pattern *regexp.Regexp) ([]*har.Exchange, error) {
+	rec, err := r.page.recordNetwork(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rec.Match(pattern), nil
+}
+
+// WaitForResponse waits up to the timeout for a response to a request whose URL
+// matches the pattern and returns the first one.
+func (r *Requests) WaitForResponse(pattern *regexp.Regexp, timeout time.Duration) (*har.Exchange, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return r.WaitForResponseWithContext(ctx, pattern)
+}
+
+// WaitForResponseWithContext waits for a response to a request whose URL matches
+// the pattern and returns the first one. It polls the performance log until the
+// context is done.
+func (r *Requests) WaitForResponseWithContext(ctx context.Context, pattern *regexp.Regexp) (*har.Exchange, error) {
+	ticker := time.NewTicker(requestsPollInterval)
+	defer ticker.Stop()
+	for {
+		exchanges, err := r.MatchWithContext(ctx, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range exchanges {
+			if e.Responded() && e.Done() {
+				return e, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to wait for a response matching %q: %w", pattern, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package navigator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+// NetworkConditions represents the network conditions emulated by the browser.
+// Network conditions are supported by Chromium based browsers only.
+type NetworkConditions struct {
+	// Offline emulates the network disconnection.
+	Offline bool
+	// Latency is the additional round trip latency.
+	Latency time.Duration
+	// Download is the maximal download throughput in bytes/sec.
+	Download int
+	// Upload is the maximal upload throughput in bytes/sec.
+	Upload int
+}
+
+var (
+	// Network3G is the network conditions of a regular 3G connection.
+	Network3G = NetworkConditions{
+		Latency:  100 * time.Millisecond,
+		Download: 750 * 1024 / 8,
+		Upload:   250 * 1024 / 8,
+	}
+
+	// NetworkSlow4G is the network conditions of a slow 4G connection.
+	NetworkSlow4G = NetworkConditions{
+		Latency:  150 * time.Millisecond,
+		Download: 1600 * 1024 / 8,
+		Upload:   750 * 1024 / 8,
+	}
+
+	// NetworkOffline is the network conditions of no connection.
+	NetworkOffline = NetworkConditions{
+		Offline: true,
+	}
+)
+
+// SetNetworkConditions sets the network conditions emulated by the browser.
+func (p *Page) SetNetworkConditions(conditions NetworkConditions) error {
+	return p.SetNetworkConditionsWithContext(context.Background(), conditions)
+}
+
+// SetNetworkConditionsWithContext sets the network conditions emulated by the browser.
+func (p *Page) SetNetworkConditionsWithContext(ctx context.Context, conditions NetworkConditions) error {
+	if err := p.session.SetNetworkConditions(ctx, session.NetworkConditions{
+		Offline:            conditions.Offline,
+		Latency:            float64(conditions.Latency) / float64(time.Millisecond),
+		DownloadThroughput: float64(conditions.Download),
+		UploadThroughput:   float64(conditions.Upload),
+	}); err != nil {
+		return fmt.Errorf("failed to set network conditions: %w", err)
+	}
+	return nil
+}
+
+// GetNetworkConditions returns the network conditions emulated by the browser.
+func (p *Page) GetNetworkConditions() (NetworkConditions, error) {
+	return p.GetNetworkConditionsWithContext(context.Background())
+}
+
+// GetNetworkConditionsWithContext returns the network conditions emulated by the browser.
+func (p *Page) GetNetworkConditionsWithContext(ctx context.Context) (NetworkConditions, error) {
+	c, err := p.session.GetNetworkConditions(ctx)
+	if err != nil {
+		return NetworkConditions{}, fmt.Errorf("failed to get network conditions: %w", err)
+	}
+	return NetworkConditions{
+		Offline:  c.Offline,
+		Latency:  time.Duration(c.Latency * float64(time.Millisecond)),
+		Download: int(c.DownloadThroughput),
+		Upload:   int(c.UploadThroughput),
+	}, nil
+}
+
+// DeleteNetworkConditions stops emulating the network conditions.
+func (p *Page) DeleteNetworkConditions() error {
+	return p.DeleteNetworkConditionsWithContext(context.Background())
+}
+
+// DeleteNetworkConditionsWithContext stops emulating the network conditions.
+func (p *Page) DeleteNetworkConditionsWithContext(ctx context.Context) error {
+	if err := p.session.DeleteNetworkConditions(ctx); err != nil {
+		return fmt.Errorf("failed to delete network conditions: %w", err)
+	}
+	return nil
+}
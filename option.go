@@ -1,19 +1,38 @@
 package navigator
 
 import (
+	"io"
+	"log/slog"
+	"maps"
 	"net/http"
+	"slices"
 	"time"
+
+	"github.com/ikawaha/navigator/devices"
+	"github.com/ikawaha/navigator/proxy"
+	"github.com/ikawaha/navigator/webdriver/session"
 )
 
 // An Option specifies configuration for a new WebDriver or Page.
 type Option func(*config)
 
 // Debug is an Option that connects the running WebDriver to stdout and stdin.
+// Unless the Logger Option is given, it installs a text handler which logs
+// the structured records of the WebDriver to stderr at the debug level.
 var Debug Option = func(c *config) {
 	b := true
 	c.debug = &b
 }
 
+// Logger provides an Option for specifying the *slog.Logger which receives the
+// structured records of starting the driver, its output and the commands of
+// the sessions with the session ID, method, path, status, duration and body.
+func Logger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
 // HTTPClient provides an Option for specifying a *http.Client
 func HTTPClient(client *http.Client) Option {
 	return func(c *config) {
@@ -21,6 +40,16 @@ func HTTPClient(client *http.Client) Option {
 	}
 }
 
+// Middleware provides an Option for wrapping the commands of the sessions
+// with the middlewares, e.g. for tracing, metrics, retries or header injection.
+// The middlewares of the successive Options are appended, and the first one
+// is the outermost. Like HTTPClient, it applies to the WebDriver, not to a Page.
+func Middleware(middlewares ...session.Middleware) Option {
+	return func(c *config) {
+		c.middleware = append(slices.Clip(c.middleware), middlewares...)
+	}
+}
+
 // Timeout provides an Option for specifying a timeout in seconds.
 func Timeout(seconds int) Option {
 	return func(c *config) {
@@ -29,6 +58,108 @@ func Timeout(seconds int) Option {
 	}
 }
 
+// DriverPath provides an Option for specifying the path to the driver
+// executable of ChromeDriver, EdgeDriver, GeckoDriver and WebKitDriver,
+// which takes precedence over the environment variable and PATH.
+func DriverPath(path string) Option {
+	return func(c *config) {
+		c.driverPath = path
+	}
+}
+
+// Output provides an Option for specifying the writers which receive the
+// stdout and stderr of the driver process line by line. Either may be nil.
+func Output(stdout, stderr io.Writer) Option {
+	return func(c *config) {
+		c.stdout = stdout
+		c.stderr = stderr
+	}
+}
+
+// LogFile provides an Option for specifying the file the stdout and stderr
+// of the driver process are appended to.
+func LogFile(path string) Option {
+	return func(c *config) {
+		c.logFile = path
+	}
+}
+
+// LogPrefix provides an Option for specifying the prefix of each output line
+// of the driver process. The prefix is templated like the URL of NewWebDriver
+// to tell the driver instances apart, e.g.
+//
+//	LogPrefix("[chromedriver:{{.Port}}] ")
+func LogPrefix(prefix string) Option {
+	return func(c *config) {
+		c.logPrefix = prefix
+	}
+}
+
+// DriverLogPath provides an Option for specifying the path of the log file
+// written by the driver itself (--log-path of chromedriver and msedgedriver).
+func DriverLogPath(path string) Option {
+	return func(c *config) {
+		c.logPath = path
+	}
+}
+
+// Verbose is an Option that makes the driver log verbosely
+// (--verbose of chromedriver and msedgedriver, -v of geckodriver).
+var Verbose Option = func(c *config) {
+	c.verbose = true
+}
+
+// Port provides an Option for specifying the fixed port of the driver.
+func Port(port int) Option {
+	return func(c *config) {
+		c.port = port
+	}
+}
+
+// PortRange provides an Option for specifying the range of the ports of the
+// driver, from which a free port is chosen.
+func PortRange(min, max int) Option {
+	return func(c *config) {
+		c.minPort = min
+		c.maxPort = max
+	}
+}
+
+// BindHost provides an Option for specifying the host the driver binds to
+// (default: "localhost").
+func BindHost(host string) Option {
+	return func(c *config) {
+		c.host = host
+	}
+}
+
+// AllowedIPs provides an Option for specifying the remote IPs allowed to
+// connect to the driver (--allowed-ips of chromedriver and msedgedriver).
+func AllowedIPs(ips ...string) Option {
+	return func(c *config) {
+		c.allowedIPs = ips
+	}
+}
+
+// TemplateField provides an Option for specifying a custom field of the URL
+// and command templates of NewWebDriver, referred as {{.Fields.name}}.
+func TemplateField(name, value string) Option {
+	return func(c *config) {
+		fields := maps.Clone(c.fields)
+		if fields == nil {
+			fields = map[string]string{}
+		}
+		fields[name] = value
+		c.fields = fields
+	}
+}
+
+// SkipVersionCheck is an Option that skips the check of the major versions
+// of the driver and the browser before opening a session.
+var SkipVersionCheck Option = func(c *config) {
+	c.skipVersionCheck = true
+}
+
 // Browser provides an Option for specifying a browser.
 func Browser(name string) Option {
 	return func(c *config) {
@@ -36,16 +167,127 @@ func Browser(name string) Option {
 	}
 }
 
+// Chrome provides an Option for specifying the configuration of Chrome,
+// which is validated before a new page is opened. e.g.
+//
+//	Chrome(ChromeConfig{Args: []string{"--no-sandbox"}, Extensions: []string{"ext.crx"}})
+func Chrome(cfg ChromeConfig) Option {
+	return func(c *config) {
+		c.chrome = &cfg
+	}
+}
+
+// Firefox provides an Option for specifying the configuration of Firefox,
+// which is validated before a new page is opened. e.g.
+//
+//	Firefox(FirefoxConfig{Args: []string{"-headless"}, Profile: "testdata/profile"})
+func Firefox(cfg FirefoxConfig) Option {
+	return func(c *config) {
+		c.firefox = &cfg
+	}
+}
+
+// Edge provides an Option for specifying the configuration of Microsoft Edge,
+// which is validated before a new page is opened.
+func Edge(cfg EdgeConfig) Option {
+	return func(c *config) {
+		c.edge = &cfg
+	}
+}
+
+// WebKit provides an Option for specifying the configuration of a WebKitGTK
+// browser driven by WebKitWebDriver, which is validated before a new page is opened.
+func WebKit(cfg WebKitConfig) Option {
+	return func(c *config) {
+		c.webkit = &cfg
+	}
+}
+
+// Selenium provides an Option for specifying the se: prefixed capabilities
+// of the Selenium Grid. e.g.
+//
+//	Selenium(SeleniumConfig{Name: "login test", RecordVideo: true})
+func Selenium(cfg SeleniumConfig) Option {
+	return func(c *config) {
+		c.selenium = &cfg
+	}
+}
+
 // ChromeOptions is used to pass additional options to Chrome via ChromeDriver.
 // e.g.
 // ChromeOptions("args", []strings{"--headless"}
 // ChromeOptions("prefs", map[string]any{"download.default_directory": "/tmp"})
+//
+// The options take precedence over the Chrome Option, except args which are appended.
 func ChromeOptions(opt string, value any) Option {
 	return func(c *config) {
-		if c.chromeOptions == nil {
-			c.chromeOptions = map[string]any{}
+		opts := maps.Clone(c.chromeOptions)
+		if opts == nil {
+			opts = map[string]any{}
 		}
-		c.chromeOptions[opt] = value
+		opts[opt] = value
+		c.chromeOptions = opts
+	}
+}
+
+// LoggingPrefs provides an Option for specifying the level of the log type
+// collected by the browser (ex. LoggingPrefs("performance", "ALL")).
+func LoggingPrefs(logType, level string) Option {
+	return func(c *config) {
+		prefs := maps.Clone(c.loggingPrefs)
+		if prefs == nil {
+			prefs = map[string]string{}
+		}
+		prefs[logType] = level
+		c.loggingPrefs = prefs
+	}
+}
+
+// UseProxy provides an Option for routing the traffic of the browser through
+// the intercepting proxy. The proxy must be started before a new page is opened.
+func UseProxy(p *proxy.Proxy) Option {
+	return func(c *config) {
+		c.proxy = p
+	}
+}
+
+// BiDi is an Option that requests a WebDriver BiDi connection for new pages,
+// which is required to register event handlers on a Page (ex. Page.OnLogEntry).
+var BiDi Option = func(c *config) {
+	c.bidi = true
+}
+
+// Headless is an Option that runs the browser without a visible window.
+var Headless Option = func(c *config) {
+	c.headless = true
+}
+
+// WindowSize provides an Option for specifying the size of the browser window
+// in pixels. The size is passed to the browser on launch and set to the window
+// of a new page.
+func WindowSize(width, height int) Option {
+	return func(c *config) {
+		c.windowSize = &windowSize{width: width, height: height}
+	}
+}
+
+// Device provides an Option for emulating the device, e.g.
+// Device(devices.Pixel7). Chrome emulates the viewport, the device pixel
+// ratio, touch events and the user agent of the device. Firefox emulates
+// the user agent, the device pixel ratio and touch events; combine it with
+// the WindowSize Option to set the viewport.
+func Device(d devices.Device) Option {
+	return func(c *config) {
+		c.device = &d
+	}
+}
+
+// Emulated provides an Option for emulating the environment on new pages.
+// Chromium based browsers emulate it on a new page with Page.Emulate, and
+// Firefox emulates it with preferences and environment variables on launch.
+func Emulated(e Emulation) Option {
+	return func(c *config) {
+		c.emulation = &e
 	}
 }
 
@@ -10,9 +10,11 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ikawaha/navigator/event"
+	"github.com/ikawaha/navigator/webdriver/bidi"
 	"github.com/ikawaha/navigator/webdriver/session"
 )
 
@@ -20,9 +22,16 @@ const aboutBlankURL = "about:blank"
 
 // A Page represents an open browser session. Pages may be created using the
 // *WebDriver.Page() method.
+// A Page is safe for concurrent use by multiple goroutines.
 type Page struct {
 	Selectable
-	logs map[string][]Log
+
+	mu       sync.Mutex // guards logs, bidi, emulated and baseEmulation
+	logs     map[string][]Log
+	bidi     *bidi.Client
+	emulated bool
+	// baseEmulation is the environment of the Emulated Option, which Reset keeps.
+	baseEmulation *Emulation
 }
 
 func newPage(session *session.Session) *Page {
@@ -51,6 +60,12 @@ func (p *Page) Destroy() error {
 
 // DestroyWithContext closes any open browsers by ending the session.
 func (p *Page) DestroyWithContext(ctx context.Context) error {
+	p.mu.Lock()
+	if p.bidi != nil {
+		_ = p.bidi.Close()
+		p.bidi = nil
+	}
+	p.mu.Unlock()
 	if err := p.session.Delete(ctx); err != nil {
 		return fmt.Errorf("failed to destroy session: %w", err)
 	}
@@ -61,6 +76,8 @@ func (p *Page) DestroyWithContext(ctx context.Context) error {
 // Unlike Destroy, Reset will permit the page to be re-used after it is called.
 // Reset is faster than Destroy, but any cookies from domains outside the current
 // domain will remain after a page is reset.
+// The emulation set by Emulate is reset, while the environment of the Emulated
+// and Device Options, which the page was created with, is kept.
 func (p *Page) Reset() error {
 	return p.ResetWithContext(context.Background())
 }
@@ -69,8 +86,13 @@ func (p *Page) Reset() error {
 // Unlike Destroy, Reset will permit the page to be re-used after it is called.
 // Reset is faster than Destroy, but any cookies from domains outside the current
 // domain will remain after a page is reset.
+// The emulation set by Emulate is reset, while the environment of the Emulated
+// and Device Options, which the page was created with, is kept.
 func (p *Page) ResetWithContext(ctx context.Context) error {
 	_ = p.ConfirmPopupWithContext(ctx)
+	if err := p.ResetEmulationWithContext(ctx); err != nil {
+		return err
+	}
 	url, err := p.URLWithContext(ctx)
 	if err != nil {
 		return err
@@ -550,6 +572,13 @@ func (p *Page) ReadNewLogs(logType string) ([]Log, error) {
 // logs and errors. Only logs since the last call to ReadNewLogs are returned.
 // Valid log types may be obtained using the LogTypes method.
 func (p *Page) ReadNewLogsWithContext(ctx context.Context, logType string) ([]Log, error) {
+	// the lock keeps the order of the logs read concurrently.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.readNewLogs(ctx, logType)
+}
+
+func (p *Page) readNewLogs(ctx context.Context, logType string) ([]Log, error) {
 	if p.logs == nil {
 		p.logs = map[string][]Log{}
 	}
@@ -589,7 +618,9 @@ func (p *Page) ReadAllLogs(logType string) ([]Log, error) {
 // and errors. All logs since the session was created are returned.
 // Valid log types may be obtained using the LogTypes method.
 func (p *Page) ReadAllLogsWithContext(ctx context.Context, logType string) ([]Log, error) {
-	if _, err := p.ReadNewLogsWithContext(ctx, logType); err != nil {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.readNewLogs(ctx, logType); err != nil {
 		return nil, err
 	}
 	ret := make([]Log, len(p.logs[logType]))
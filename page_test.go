@@ -0,0 +1,88 @@
+package navigator
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+	"github.com/ikawaha/navigator/webdriver/webdrivertest"
+)
+
+// startTestDriver starts the web driver for a fake web driver server.
+func startTestDriver(t *testing.T) (*WebDriver, *webdrivertest.Server) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("sleep command is not available")
+	}
+	srv := webdrivertest.NewServer()
+	t.Cleanup(func() {
+		if got := srv.Overlapped(); len(got) != 0 {
+			t.Errorf("want no concurrent commands on a session, got %+v", got)
+		}
+		srv.Close()
+	})
+	// the commands are delayed, so that the commands sent concurrently overlap.
+	srv.Inject(webdrivertest.Fault{Delay: time.Millisecond})
+	srv.HandleLog("browser", session.Log{Message: "console.log (app.js:1:2)", Level: "INFO", Timestamp: time.Now().UnixMilli()})
+	// the fake server stands for the process, which only has to keep running.
+	driver := NewWebDriver(srv.URL, []string{"sleep", "60"})
+	if err := driver.Start(context.Background()); err != nil {
+		t.Fatalf("Start() failed: unexpected error %v", err)
+	}
+	t.Cleanup(func() { _ = driver.Stop() })
+	return driver, srv
+}
+
+func TestPage_Parallel(t *testing.T) {
+	driver, _ := startTestDriver(t)
+
+	const pages, workers = 8, 4
+	var wg sync.WaitGroup
+	for i := 0; i < pages; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			page, err := driver.NewPage()
+			if err != nil {
+				t.Errorf("NewPage() failed: unexpected error %v", err)
+				return
+			}
+			url := fmt.Sprintf("http://example.com/%d", i)
+			var pw sync.WaitGroup
+			for j := 0; j < workers; j++ {
+				pw.Add(1)
+				go func() {
+					defer pw.Done()
+					if err := page.Navigate(url); err != nil {
+						t.Errorf("Navigate() failed: unexpected error %v", err)
+					}
+					if got, err := page.URL(); err != nil {
+						t.Errorf("URL() failed: unexpected error %v", err)
+					} else if got != url {
+						t.Errorf("want %q, got %q", url, got)
+					}
+					if _, err := page.ReadNewLogs("browser"); err != nil {
+						t.Errorf("ReadNewLogs() failed: unexpected error %v", err)
+					}
+				}()
+			}
+			pw.Wait()
+			if logs, err := page.ReadAllLogs("browser"); err != nil {
+				t.Errorf("ReadAllLogs() failed: unexpected error %v", err)
+			} else if want := workers + 1; len(logs) != want {
+				t.Errorf("want %d logs, got %d", want, len(logs))
+			}
+			if err := page.Destroy(); err != nil {
+				t.Errorf("Destroy() failed: unexpected error %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := driver.Sessions(); len(got) != 0 {
+		t.Errorf("want no sessions, got %d", len(got))
+	}
+}
@@ -0,0 +1,262 @@
+package navigator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by Acquire after the pool is closed.
+var ErrPoolClosed = errors.New("pool closed")
+
+// DefaultPoolSize is the maximum number of pages of a pool created with a non-positive size.
+const DefaultPoolSize = 4
+
+// A Pool keeps the pages of a WebDriver for reuse, so that parallel tests do
+// not have to start a browser session each. The pages are created lazily up to
+// the maximum size and reset when they are released. The exported fields have
+// to be set before the first Acquire. A Pool is safe for concurrent use by
+// multiple goroutines.
+type Pool struct {
+	// IdleTimeout is the duration an idle page is kept before it is destroyed.
+	// If zero, idle pages are kept until the pool is closed.
+	IdleTimeout time.Duration
+	// HealthCheck checks an idle page before it is reused. The broken page is
+	// destroyed and replaced with a new one. If nil, the URL of the page is retrieved.
+	HealthCheck func(ctx context.Context, page *Page) error
+
+	driver  *WebDriver
+	options []Option
+	slots   chan struct{}
+	closed  chan struct{} // closed by Close to wake up the waiting Acquire
+
+	mu    sync.Mutex // guards idle, inUse, stats and the closing of closed
+	idle  []idlePage
+	inUse map[*Page]struct{}
+	stats PoolStats
+}
+
+type idlePage struct {
+	page  *Page
+	since time.Time
+}
+
+// PoolStats is the statistics of a pool.
+type PoolStats struct {
+	// MaxSize is the maximum number of pages.
+	MaxSize int
+	// Idle and InUse are the numbers of the idle and the acquired pages.
+	Idle  int
+	InUse int
+	// Created is the number of the pages created.
+	Created int
+	// Reused is the number of the idle pages acquired again.
+	Reused int
+	// Discarded is the number of the pages destroyed because they failed the
+	// health check or the reset.
+	Discarded int
+	// Expired is the number of the pages destroyed after the idle timeout.
+	Expired int
+	// Waits is the number of the acquisitions which found the pool full, including
+	// the ones which timed out or failed because the pool was closed.
+	Waits int
+}
+
+// NewPool returns a pool of at most size pages created by the driver with the options.
+func NewPool(driver *WebDriver, size int, options ...Option) *Pool {
+	if size <= 0 {
+		size = DefaultPoolSize
+	}
+	return &Pool{
+		driver:  driver,
+		options: options,
+		slots:   make(chan struct{}, size),
+		closed:  make(chan struct{}),
+		inUse:   map[*Page]struct{}{},
+		stats:   PoolStats{MaxSize: size},
+	}
+}
+
+// Acquire returns an idle page, or a new page if there are none. If the pool
+// is full, it waits until a page is released, the context is done or the pool
+// is closed.
+func (p *Pool) Acquire(ctx context.Context) (*Page, error) {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		p.mu.Lock()
+		p.stats.Waits++
+		p.mu.Unlock()
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to acquire page: %w", ctx.Err())
+		case <-p.closed:
+			return nil, ErrPoolClosed
+		}
+	}
+	page, err := p.acquire(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+	return page, nil
+}
+
+func (p *Pool) acquire(ctx context.Context) (*Page, error) {
+	p.prune()
+	for {
+		p.mu.Lock()
+		if p.isClosed() {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+			break
+		}
+		// the most recently used page is reused, so that the others expire.
+		page := p.idle[len(p.idle)-1].page
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+
+		if err := p.healthCheck(ctx, page); err != nil {
+			p.discard(ctx, page)
+			continue
+		}
+		p.mu.Lock()
+		p.inUse[page] = struct{}{}
+		p.stats.Reused++
+		p.mu.Unlock()
+		return page, nil
+	}
+	page, err := p.driver.NewPageWithContext(ctx, p.options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page: %w", err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats.Created++
+	if p.isClosed() {
+		_ = page.DestroyWithContext(ctx)
+		return nil, ErrPoolClosed
+	}
+	p.inUse[page] = struct{}{}
+	return page, nil
+}
+
+func (p *Pool) healthCheck(ctx context.Context, page *Page) error {
+	if p.HealthCheck != nil {
+		return p.HealthCheck(ctx, page)
+	}
+	_, err := page.URLWithContext(ctx)
+	return err
+}
+
+// discard destroys the broken page.
+func (p *Pool) discard(ctx context.Context, page *Page) {
+	_ = page.DestroyWithContext(ctx)
+	p.mu.Lock()
+	p.stats.Discarded++
+	p.mu.Unlock()
+}
+
+// Release resets the page and returns it to the pool. The page which fails
+// to reset is destroyed.
+func (p *Pool) Release(page *Page) error {
+	return p.ReleaseWithContext(context.Background(), page)
+}
+
+// ReleaseWithContext resets the page and returns it to the pool. The page
+// which fails to reset is destroyed.
+func (p *Pool) ReleaseWithContext(ctx context.Context, page *Page) error {
+	p.mu.Lock()
+	if _, ok := p.inUse[page]; !ok {
+		p.mu.Unlock()
+		return fmt.Errorf("page not acquired from the pool")
+	}
+	delete(p.inUse, page)
+	p.mu.Unlock()
+	defer func() { <-p.slots }()
+
+	if err := page.ResetWithContext(ctx); err != nil {
+		p.discard(ctx, page)
+		return fmt.Errorf("failed to reset page: %w", err)
+	}
+	p.mu.Lock()
+	if p.isClosed() {
+		p.mu.Unlock()
+		return page.DestroyWithContext(ctx)
+	}
+	p.idle = append(p.idle, idlePage{page: page, since: time.Now()})
+	p.mu.Unlock()
+	if p.IdleTimeout > 0 {
+		time.AfterFunc(p.IdleTimeout, p.prune)
+	}
+	return nil
+}
+
+// prune destroys the pages idle for longer than the idle timeout.
+func (p *Pool) prune() {
+	if p.IdleTimeout <= 0 {
+		return
+	}
+	var expired []*Page
+	p.mu.Lock()
+	deadline := time.Now().Add(-p.IdleTimeout)
+	// the idle pages are ordered by the time they were released.
+	i := 0
+	for i < len(p.idle) && !p.idle[i].since.After(deadline) {
+		expired = append(expired, p.idle[i].page)
+		i++
+	}
+	p.idle = p.idle[i:]
+	p.mu.Unlock()
+	for _, page := range expired {
+		_ = page.Destroy()
+	}
+	p.mu.Lock()
+	p.stats.Expired += len(expired)
+	p.mu.Unlock()
+}
+
+// Stats returns the statistics of the pool.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Idle = len(p.idle)
+	stats.InUse = len(p.inUse)
+	return stats
+}
+
+func (p *Pool) isClosed() bool {
+	select {
+	case <-p.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close destroys the idle pages. The pages in use are destroyed when they are
+// released, and Acquire fails with ErrPoolClosed after the pool is closed,
+// including the calls waiting for a page.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.isClosed() {
+		close(p.closed)
+	}
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	var errs []error
+	for _, v := range idle {
+		if err := v.page.DestroyWithContext(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
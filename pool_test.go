@@ -0,0 +1,213 @@
+package navigator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	driver, _ := startTestDriver(t)
+	pool := NewPool(driver, 2)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			page, err := pool.Acquire(ctx)
+			if err != nil {
+				t.Errorf("Acquire() failed: unexpected error %v", err)
+				return
+			}
+			if err := page.Navigate("http://example.com"); err != nil {
+				t.Errorf("Navigate() failed: unexpected error %v", err)
+			}
+			if err := pool.Release(page); err != nil {
+				t.Errorf("Release() failed: unexpected error %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	stats := pool.Stats()
+	if stats.Created > 2 || stats.Created+stats.Reused != 10 {
+		t.Errorf("want at most 2 pages created for 10 acquisitions, got %+v", stats)
+	}
+	if stats.Idle != stats.Created || stats.InUse != 0 {
+		t.Errorf("want all pages idle, got %+v", stats)
+	}
+	// the released page is reset.
+	page, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() failed: unexpected error %v", err)
+	}
+	if got, err := page.URL(); err != nil || got != aboutBlankURL {
+		t.Errorf("want %q, got %q (%v)", aboutBlankURL, got, err)
+	}
+	if err := pool.Release(page); err != nil {
+		t.Errorf("Release() failed: unexpected error %v", err)
+	}
+	if err := pool.Release(page); err == nil {
+		t.Errorf("expected error, but nil")
+	}
+	if err := pool.Close(ctx); err != nil {
+		t.Errorf("Close() failed: unexpected error %v", err)
+	}
+	if _, err := pool.Acquire(ctx); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("want %v, got %v", ErrPoolClosed, err)
+	}
+	if got := driver.Sessions(); len(got) != 0 {
+		t.Errorf("want no sessions, got %d", len(got))
+	}
+}
+
+func TestPool_Wait(t *testing.T) {
+	driver, _ := startTestDriver(t)
+	pool := NewPool(driver, 1)
+	defer pool.Close(context.Background())
+
+	page, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() failed: unexpected error %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+	if err := pool.Release(page); err != nil {
+		t.Fatalf("Release() failed: unexpected error %v", err)
+	}
+	if got, want := pool.Stats().Waits, 1; got != want {
+		t.Errorf("want %d waits, got %d", want, got)
+	}
+}
+
+func TestPool_CloseWhileWaiting(t *testing.T) {
+	driver, _ := startTestDriver(t)
+	pool := NewPool(driver, 1)
+
+	page, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() failed: unexpected error %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := pool.Acquire(ctx)
+		errc <- err
+	}()
+	for pool.Stats().Waits == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := pool.Close(context.Background()); err != nil {
+		t.Fatalf("Close() failed: unexpected error %v", err)
+	}
+	if err := <-errc; !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("want %v, got %v", ErrPoolClosed, err)
+	}
+	if err := pool.Release(page); err != nil {
+		t.Fatalf("Release() failed: unexpected error %v", err)
+	}
+}
+
+func TestPool_HealthCheck(t *testing.T) {
+	driver, server := startTestDriver(t)
+	pool := NewPool(driver, 1)
+	defer pool.Close(context.Background())
+	ctx := context.Background()
+
+	page, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() failed: unexpected error %v", err)
+	}
+	if err := pool.Release(page); err != nil {
+		t.Fatalf("Release() failed: unexpected error %v", err)
+	}
+	server.Lose(page.Session().ID())
+	got, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() failed: unexpected error %v", err)
+	}
+	if got == page {
+		t.Errorf("the broken page is reused")
+	}
+	if stats := pool.Stats(); stats.Discarded != 1 || stats.Created != 2 {
+		t.Errorf("want the broken page discarded and replaced, got %+v", stats)
+	}
+	if err := pool.Release(got); err != nil {
+		t.Errorf("Release() failed: unexpected error %v", err)
+	}
+}
+
+func TestPool_IdleTimeout(t *testing.T) {
+	driver, _ := startTestDriver(t)
+	pool := NewPool(driver, 1)
+	pool.IdleTimeout = 50 * time.Millisecond
+	defer pool.Close(context.Background())
+
+	page, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire() failed: unexpected error %v", err)
+	}
+	if err := pool.Release(page); err != nil {
+		t.Fatalf("Release() failed: unexpected error %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Stats().Expired == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats := pool.Stats(); stats.Expired != 1 || stats.Idle != 0 {
+		t.Errorf("want the idle page expired, got %+v", stats)
+	}
+	if got := driver.Sessions(); len(got) != 0 {
+		t.Errorf("want no sessions, got %d", len(got))
+	}
+}
+
+func TestPool_Emulation(t *testing.T) {
+	driver, server := startTestDriver(t)
+	pool := NewPool(driver, 1, Browser("chrome"), Emulated(Emulation{Timezone: "Asia/Tokyo"}))
+	defer pool.Close(context.Background())
+	ctx := context.Background()
+
+	page, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() failed: unexpected error %v", err)
+	}
+	if err := page.Navigate("http://example.com"); err != nil {
+		t.Fatalf("Navigate() failed: unexpected error %v", err)
+	}
+	if err := page.Emulate(Emulation{Timezone: "Europe/Paris"}); err != nil {
+		t.Fatalf("Emulate() failed: unexpected error %v", err)
+	}
+	if err := pool.Release(page); err != nil {
+		t.Fatalf("Release() failed: unexpected error %v", err)
+	}
+	got, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() failed: unexpected error %v", err)
+	}
+	if got != page {
+		t.Fatalf("want the page reused")
+	}
+	defer pool.Release(got)
+
+	// the timezone of the page is reset to the one of the Option.
+	var timezone any
+	for _, c := range server.CDPCommands() {
+		switch c.Cmd {
+		case "Emulation.setTimezoneOverride":
+			timezone = c.Params["timezoneId"]
+		case "Emulation.clearDeviceMetricsOverride", "Emulation.setUserAgentOverride":
+			t.Errorf("unexpected command %s %v, the device emulation is reset", c.Cmd, c.Params)
+		}
+	}
+	if timezone != "Asia/Tokyo" {
+		t.Errorf("want %q, got %v", "Asia/Tokyo", timezone)
+	}
+}
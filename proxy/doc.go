@@ -0,0 +1,3 @@
+// Package proxy is an intercepting HTTP(S) proxy that lets tests stub, delay,
+// fail and block the requests issued by the browser and record the traffic.
+package proxy
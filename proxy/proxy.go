@@ -0,0 +1,414 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Proxy is an in-process HTTP(S) proxy. HTTPS requests are intercepted with
+// certificates issued by the CA, so the browser has to accept them (navigator
+// pages accept invalid certificates by default).
+type Proxy struct {
+	// CA issues the certificates of intercepted HTTPS hosts. A new CA is
+	// created on Start if nil.
+	CA *CA
+	// Transport is used to forward requests to the upstream servers.
+	// The default is a clone of http.DefaultTransport without a proxy.
+	Transport http.RoundTripper
+
+	mu         sync.Mutex
+	routes     []route
+	blocked    hostMatcher
+	firstParty hostMatcher
+	records    []Record
+	listener   net.Listener
+	server     *http.Server
+	tunnels    map[net.Conn]struct{} // hijacked CONNECT connections closed on Stop
+}
+
+// Record is a request that went through the proxy.
+type Record struct {
+	Method   string
+	URL      string
+	Status   int
+	Started  time.Time
+	Duration time.Duration
+	// Stubbed reports whether the response was served by a route.
+	Stubbed bool
+	// Blocked reports whether the request was blocked.
+	Blocked bool
+	// Failed reports whether the request was failed by a route or the upstream.
+	Failed bool
+}
+
+// New creates a proxy.
+func New() *Proxy {
+	return &Proxy{}
+}
+
+// Start starts listening on a free port of the loopback interface.
+func (p *Proxy) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.server != nil {
+		return errors.New("already running")
+	}
+	if p.CA == nil {
+		ca, err := NewCA()
+		if err != nil {
+			return err
+		}
+		p.CA = ca
+	}
+	if p.Transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.Proxy = nil
+		p.Transport = t
+	}
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	p.listener = l
+	p.server = &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func(server *http.Server, l net.Listener) {
+		_ = server.Serve(l)
+	}(p.server, l)
+	return nil
+}
+
+// Stop stops the proxy.
+func (p *Proxy) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.server == nil {
+		return errors.New("already stopped")
+	}
+	err := p.server.Close()
+	// the server does not track the hijacked connections, whose closing
+	// also stops the servers of the intercepted hosts on them.
+	for conn := range p.tunnels {
+		_ = conn.Close()
+	}
+	p.tunnels = nil
+	p.server = nil
+	p.listener = nil
+	return err
+}
+
+// Addr returns the address the proxy listens on, or an empty string
+// if the proxy is not running.
+func (p *Proxy) Addr() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener == nil {
+		return ""
+	}
+	return p.listener.Addr().String()
+}
+
+// Route registers the action for requests whose URL matches the pattern.
+// Routes are tried in the order they were registered.
+func (p *Proxy) Route(pattern *regexp.Regexp, action Action) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.routes = append(p.routes, route{pattern: pattern, action: action})
+}
+
+// Block fails the requests to the hosts and their subdomains.
+func (p *Proxy) Block(hosts ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.blocked = append(p.blocked, hosts...)
+}
+
+// BlockThirdParty fails the requests to any host other than the hosts
+// and their subdomains.
+func (p *Proxy) BlockThirdParty(hosts ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.firstParty = append(p.firstParty, hosts...)
+}
+
+// Records returns the requests that went through the proxy.
+func (p *Proxy) Records() []Record {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ret := make([]Record, len(p.records))
+	copy(ret, p.records)
+	return ret
+}
+
+// ClearRecords clears the recorded requests.
+func (p *Proxy) ClearRecords() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.records = nil
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.serveConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "navigator proxy: not a proxy request", http.StatusBadRequest)
+		return
+	}
+	p.serve(w, r)
+}
+
+func (p *Proxy) serve(w http.ResponseWriter, r *http.Request) {
+	rw := &recordWriter{ResponseWriter: w}
+	started := time.Now()
+	p.dispatch(rw, r)
+	record := Record{
+		Method:   r.Method,
+		URL:      r.URL.String(),
+		Status:   rw.status,
+		Started:  started,
+		Duration: time.Since(started),
+		Stubbed:  rw.stubbed,
+		Blocked:  rw.blocked,
+		Failed:   rw.failed,
+	}
+	p.mu.Lock()
+	p.records = append(p.records, record)
+	p.mu.Unlock()
+}
+
+func (p *Proxy) dispatch(w *recordWriter, r *http.Request) {
+	p.mu.Lock()
+	blocked := p.blocked.match(r.URL.Host) ||
+		(len(p.firstParty) > 0 && !p.firstParty.match(r.URL.Host))
+	routes := make([]route, len(p.routes))
+	copy(routes, p.routes)
+	p.mu.Unlock()
+
+	if blocked {
+		w.blocked = true
+		abort(w)
+		return
+	}
+	next := http.HandlerFunc(p.forward)
+	for _, v := range routes {
+		if v.pattern.MatchString(r.URL.String()) {
+			v.action(w, r, next)
+			return
+		}
+	}
+	next.ServeHTTP(w, r)
+}
+
+// hop-by-hop headers. See: RFC 7230, section 6.1
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	req := r.Clone(r.Context())
+	req.RequestURI = ""
+	for _, h := range hopHeaders {
+		req.Header.Del(h)
+	}
+	resp, err := p.Transport.RoundTrip(req)
+	if err != nil {
+		abort(w)
+		return
+	}
+	defer resp.Body.Close()
+	for _, h := range hopHeaders {
+		resp.Header.Del(h)
+	}
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func (p *Proxy) serveConnect(w http.ResponseWriter, r *http.Request) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "navigator proxy: hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	if !p.track(conn) {
+		_ = conn.Close()
+		return
+	}
+	defer p.untrack(conn)
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		_ = conn.Close()
+		return
+	}
+	host := r.URL.Host
+	tlsConn := tls.Server(conn, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			name := hello.ServerName
+			if name == "" {
+				name = hostname(host)
+			}
+			return p.CA.certificate(name)
+		},
+		NextProtos: []string{"http/1.1"},
+	})
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.URL.Scheme = "https"
+			r.URL.Host = r.Host
+			if r.URL.Host == "" {
+				r.URL.Host = host
+			}
+			p.serve(w, r)
+		}),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	_ = server.Serve(newConnListener(tlsConn))
+}
+
+// track registers the hijacked connection to be closed on Stop. It returns
+// false if the proxy is already stopped.
+func (p *Proxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.server == nil {
+		return false
+	}
+	if p.tunnels == nil {
+		p.tunnels = map[net.Conn]struct{}{}
+	}
+	p.tunnels[conn] = struct{}{}
+	return true
+}
+
+func (p *Proxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tunnels, conn)
+}
+
+func hostname(hostport string) string {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport
+	}
+	return host
+}
+
+// connListener is a net.Listener that accepts a single connection.
+type connListener struct {
+	conn   net.Conn
+	closed chan struct{}
+}
+
+func newConnListener(conn net.Conn) *connListener {
+	return &connListener{
+		conn:   &notifyConn{Conn: conn},
+		closed: make(chan struct{}),
+	}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	if c := l.conn; c != nil {
+		l.conn = nil
+		c.(*notifyConn).closed = l.closed
+		return c, nil
+	}
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *connListener) Close() error {
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return &net.TCPAddr{}
+}
+
+// notifyConn signals the listener when the connection is closed.
+type notifyConn struct {
+	net.Conn
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (c *notifyConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return c.Conn.Close()
+}
+
+// recordWriter records the result of a response.
+type recordWriter struct {
+	http.ResponseWriter
+	status  int
+	stubbed bool
+	blocked bool
+	failed  bool
+}
+
+func (w *recordWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijacking not supported")
+	}
+	return hj.Hijack()
+}
+
+func markStubbed(w http.ResponseWriter) {
+	if rw, ok := w.(*recordWriter); ok {
+		rw.stubbed = true
+	}
+}
+
+func markFailed(w http.ResponseWriter) {
+	if rw, ok := w.(*recordWriter); ok {
+		rw.failed = true
+	}
+}
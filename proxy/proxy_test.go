@@ -0,0 +1,199 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func startProxy(t *testing.T) (*Proxy, *http.Client) {
+	t.Helper()
+	p := New()
+	upstream := http.DefaultTransport.(*http.Transport).Clone()
+	upstream.Proxy = nil
+	upstream.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	p.Transport = upstream
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("p.Start() failed: unexpected error %v", err)
+	}
+	t.Cleanup(func() {
+		_ = p.Stop()
+	})
+	proxyURL, err := url.Parse("http://" + p.Addr())
+	if err != nil {
+		t.Fatalf("url.Parse() failed: unexpected error %v", err)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{RootCAs: p.CA.CertPool()},
+		},
+		Timeout: 5 * time.Second,
+	}
+	return p, client
+}
+
+func get(t *testing.T, client *http.Client, u string) (int, string, error) {
+	t.Helper()
+	resp, err := client.Get(u)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("io.ReadAll() failed: unexpected error %v", err)
+	}
+	return resp.StatusCode, string(b), nil
+}
+
+func TestProxy(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "upstream "+r.URL.Path)
+	})
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	ts2 := httptest.NewTLSServer(handler)
+	defer ts2.Close()
+
+	t.Run("forward", func(t *testing.T) {
+		_, client := startProxy(t)
+		for _, u := range []string{ts.URL, ts2.URL} {
+			status, body, err := get(t, client, u+"/hello")
+			if err != nil {
+				t.Fatalf("client.Get(%q) failed: unexpected error %v", u, err)
+			}
+			if status != http.StatusOK || body != "upstream /hello" {
+				t.Errorf("want 200 %q, got %d %q", "upstream /hello", status, body)
+			}
+		}
+	})
+	t.Run("stub", func(t *testing.T) {
+		p, client := startProxy(t)
+		p.Route(regexp.MustCompile(`/api/`), Respond(http.StatusTeapot, http.Header{"X-Stub": {"1"}}, []byte("stubbed")))
+		for _, u := range []string{ts.URL, ts2.URL} {
+			status, body, err := get(t, client, u+"/api/items")
+			if err != nil {
+				t.Fatalf("client.Get(%q) failed: unexpected error %v", u, err)
+			}
+			if status != http.StatusTeapot || body != "stubbed" {
+				t.Errorf("want 418 %q, got %d %q", "stubbed", status, body)
+			}
+		}
+		records := p.Records()
+		if len(records) != 2 {
+			t.Fatalf("want 2 records, got %d", len(records))
+		}
+		for _, r := range records {
+			if !r.Stubbed || r.Status != http.StatusTeapot {
+				t.Errorf("want stubbed record with status 418, got %+v", r)
+			}
+		}
+	})
+	t.Run("delay", func(t *testing.T) {
+		p, client := startProxy(t)
+		p.Route(regexp.MustCompile(`/slow`), Delay(200*time.Millisecond))
+		start := time.Now()
+		status, _, err := get(t, client, ts.URL+"/slow")
+		if err != nil {
+			t.Fatalf("client.Get() failed: unexpected error %v", err)
+		}
+		if status != http.StatusOK {
+			t.Errorf("want 200, got %d", status)
+		}
+		if d := time.Since(start); d < 200*time.Millisecond {
+			t.Errorf("want a delay of at least 200ms, got %v", d)
+		}
+	})
+	t.Run("fail", func(t *testing.T) {
+		p, client := startProxy(t)
+		p.Route(regexp.MustCompile(`/broken`), Fail)
+		if _, _, err := get(t, client, ts.URL+"/broken"); err == nil {
+			t.Errorf("expected error, but nil")
+		}
+		if records := p.Records(); len(records) != 1 || !records[0].Failed {
+			t.Errorf("want a failed record, got %+v", records)
+		}
+	})
+	t.Run("block third party", func(t *testing.T) {
+		p, client := startProxy(t)
+		p.BlockThirdParty("localhost")
+		if _, _, err := get(t, client, ts.URL+"/tracker"); err == nil {
+			t.Errorf("expected error, but nil")
+		}
+		if records := p.Records(); len(records) != 1 || !records[0].Blocked {
+			t.Errorf("want a blocked record, got %+v", records)
+		}
+	})
+	t.Run("block", func(t *testing.T) {
+		p, client := startProxy(t)
+		p.Block("127.0.0.1")
+		if _, _, err := get(t, client, ts.URL+"/hello"); err == nil {
+			t.Errorf("expected error, but nil")
+		}
+	})
+}
+
+func TestProxy_StartStop(t *testing.T) {
+	p := New()
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("p.Start() failed: unexpected error %v", err)
+	}
+	if err := p.Start(context.Background()); err == nil {
+		t.Errorf("expected error, but nil")
+	}
+	if p.Addr() == "" {
+		t.Errorf("expected address is not empty")
+	}
+	if err := p.Stop(); err != nil {
+		t.Errorf("p.Stop() failed: unexpected error %v", err)
+	}
+	if err := p.Stop(); err == nil {
+		t.Errorf("expected error, but nil")
+	}
+	if got := p.Addr(); got != "" {
+		t.Errorf("expected address is empty, but %q", got)
+	}
+}
+
+func TestProxy_StopClosesTunnels(t *testing.T) {
+	p, _ := startProxy(t)
+	conn, err := net.Dial("tcp", p.Addr())
+	if err != nil {
+		t.Fatalf("net.Dial() failed: unexpected error %v", err)
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n\r\n"); err != nil {
+		t.Fatalf("io.WriteString() failed: unexpected error %v", err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("http.ReadResponse() failed: unexpected error %v", err)
+	}
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("want %d, got %d", want, got)
+	}
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: "example.com", RootCAs: p.CA.CertPool()})
+	if err := tlsConn.Handshake(); err != nil {
+		t.Fatalf("Handshake() failed: unexpected error %v", err)
+	}
+
+	if err := p.Stop(); err != nil {
+		t.Fatalf("p.Stop() failed: unexpected error %v", err)
+	}
+	_ = tlsConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = tlsConn.Read(make([]byte, 1))
+	var ne net.Error
+	if err == nil || errors.As(err, &ne) && ne.Timeout() {
+		t.Errorf("want the tunnel closed, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// An Action handles an intercepted request. The next handler forwards the
+// request to the upstream server.
+type Action func(w http.ResponseWriter, r *http.Request, next http.Handler)
+
+// Respond returns an Action that stubs the response with the status, header
+// and body instead of forwarding the request.
+func Respond(status int, header http.Header, body []byte) Action {
+	return func(w http.ResponseWriter, r *http.Request, next http.Handler) {
+		markStubbed(w)
+		for k, vs := range header {
+			for _, v := range vs {
+				w.Header().Add(k, v)
+			}
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}
+}
+
+// Handle returns an Action that serves the request with the handler instead of
+// forwarding it.
+func Handle(h http.Handler) Action {
+	return func(w http.ResponseWriter, r *http.Request, next http.Handler) {
+		markStubbed(w)
+		h.ServeHTTP(w, r)
+	}
+}
+
+// Delay returns an Action that forwards the request after the duration.
+func Delay(d time.Duration) Action {
+	return func(w http.ResponseWriter, r *http.Request, next http.Handler) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(d):
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// Fail is an Action that fails the request by closing the connection without
+// a response, as a network error does.
+func Fail(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	abort(w)
+}
+
+func abort(w http.ResponseWriter) {
+	markFailed(w)
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	_ = conn.Close()
+}
+
+type route struct {
+	pattern *regexp.Regexp
+	action  Action
+}
+
+// hostMatcher matches a host and its subdomains.
+type hostMatcher []string
+
+func (m hostMatcher) match(host string) bool {
+	host = strings.ToLower(hostname(host))
+	for _, v := range m {
+		v = strings.ToLower(v)
+		if host == v || strings.HasSuffix(host, "."+v) {
+			return true
+		}
+	}
+	return false
+}
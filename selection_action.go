@@ -181,7 +181,7 @@ func (s *Selection) SelectWithContext(ctx context.Context, text string) error {
 	return s.forEachElement(ctx, func(selectedElement *session.Element) error {
 		optionXPath := fmt.Sprintf(`./option[normalize-space()="%s"]`, text)
 		optionToSelect := selector{Type: xPathType, Value: optionXPath}
-		options, err := selectedElement.Session.GetElements(ctx, optionToSelect.SessionSelector())
+		options, err := selectedElement.Session.GetElements(ctx, optionToSelect.SessionSelector(selectedElement.Session.Dialect()))
 		if err != nil {
 			return fmt.Errorf("failed to select specified option for %s: %w", s, err)
 		}
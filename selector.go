@@ -2,6 +2,7 @@ package navigator
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ikawaha/navigator/webdriver/session"
 )
@@ -44,7 +45,22 @@ func (s selector) String() string {
 	return s.Type.format(s.Value) + suffix
 }
 
-func (s selector) SessionSelector() session.Selector {
+// SessionSelector returns the selector sent to the web driver speaking the
+// dialect. The W3C dialect has no "class name", "id" and "name" locator
+// strategies, so they are converted to CSS selectors.
+//
+//nolint:exhaustive
+func (s selector) SessionSelector(dialect session.Dialect) session.Selector {
+	if dialect == session.DialectW3C {
+		switch s.Type {
+		case classType:
+			return session.Selector{Using: "css selector", Value: "." + cssIdentifier(s.Value)}
+		case idType:
+			return session.Selector{Using: "css selector", Value: "#" + cssIdentifier(s.Value)}
+		case nameType:
+			return session.Selector{Using: "css selector", Value: "[name=" + cssString(s.Value) + "]"}
+		}
+	}
 	return session.Selector{
 		Using: s.selectorType(),
 		Value: s.value(),
@@ -91,3 +107,53 @@ func (s selector) value() string {
 		return s.Value
 	}
 }
+
+// cssIdentifier escapes the value as a CSS identifier in the way of CSS.escape.
+//
+// See: https://drafts.csswg.org/cssom/#serialize-an-identifier
+func cssIdentifier(value string) string {
+	var b strings.Builder
+	runes := []rune(value)
+	for i, r := range runes {
+		switch {
+		case r == 0:
+			b.WriteRune('\uFFFD')
+		case r < 0x20 || r == 0x7f,
+			i == 0 && '0' <= r && r <= '9',
+			i == 1 && '0' <= r && r <= '9' && runes[0] == '-':
+			fmt.Fprintf(&b, "\\%x ", r)
+		case i == 0 && r == '-' && len(runes) == 1:
+			b.WriteString("\\-")
+		case r >= 0x80 || r == '-' || r == '_' ||
+			'0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// cssString quotes the value as a CSS string.
+//
+// See: https://drafts.csswg.org/cssom/#serialize-a-string
+func cssString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == 0:
+			b.WriteRune('\uFFFD')
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, "\\%x ", r)
+		case r == '"' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package navigator
+
+import (
+	"testing"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+func Test_selector_SessionSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector selector
+		dialect  session.Dialect
+		want     session.Selector
+	}{
+		{
+			name:     "class (legacy)",
+			selector: selector{Type: classType, Value: "item"},
+			dialect:  session.DialectLegacy,
+			want:     session.Selector{Using: "class name", Value: "item"},
+		},
+		{
+			name:     "class (W3C)",
+			selector: selector{Type: classType, Value: "item"},
+			dialect:  session.DialectW3C,
+			want:     session.Selector{Using: "css selector", Value: ".item"},
+		},
+		{
+			name:     "class with special characters (W3C)",
+			selector: selector{Type: classType, Value: "col-md:6"},
+			dialect:  session.DialectW3C,
+			want:     session.Selector{Using: "css selector", Value: `.col-md\:6`},
+		},
+		{
+			name:     "id (legacy)",
+			selector: selector{Type: idType, Value: "login"},
+			dialect:  session.DialectLegacy,
+			want:     session.Selector{Using: "id", Value: "login"},
+		},
+		{
+			name:     "id (W3C)",
+			selector: selector{Type: idType, Value: "login"},
+			dialect:  session.DialectW3C,
+			want:     session.Selector{Using: "css selector", Value: "#login"},
+		},
+		{
+			name:     "id starting with a digit (W3C)",
+			selector: selector{Type: idType, Value: "1st.item"},
+			dialect:  session.DialectW3C,
+			want:     session.Selector{Using: "css selector", Value: `#\31 st\.item`},
+		},
+		{
+			name:     "name (legacy)",
+			selector: selector{Type: nameType, Value: "email"},
+			dialect:  session.DialectLegacy,
+			want:     session.Selector{Using: "name", Value: "email"},
+		},
+		{
+			name:     "name (W3C)",
+			selector: selector{Type: nameType, Value: "email"},
+			dialect:  session.DialectW3C,
+			want:     session.Selector{Using: "css selector", Value: `[name="email"]`},
+		},
+		{
+			name:     "name with quotes (W3C)",
+			selector: selector{Type: nameType, Value: `user["name"]`},
+			dialect:  session.DialectW3C,
+			want:     session.Selector{Using: "css selector", Value: `[name="user[\"name\"]"]`},
+		},
+		{
+			name:     "css (W3C)",
+			selector: selector{Type: cssType, Value: "div > p"},
+			dialect:  session.DialectW3C,
+			want:     session.Selector{Using: "css selector", Value: "div > p"},
+		},
+		{
+			name:     "link (W3C)",
+			selector: selector{Type: linkType, Value: "Next"},
+			dialect:  session.DialectW3C,
+			want:     session.Selector{Using: "link text", Value: "Next"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.selector.SessionSelector(tt.dialect); got != tt.want {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package navigator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+// SeleniumConfig is the configuration of the Selenium Grid passed as the se:
+// prefixed capabilities.
+//
+// See: https://www.selenium.dev/documentation/grid/configuration/
+type SeleniumConfig struct {
+	// Name is the name of the session shown in the Grid UI (se:name).
+	Name string
+	// RecordVideo records the video of the session on the dynamic Grid (se:recordVideo).
+	RecordVideo bool
+	// ScreenResolution is the resolution of the screen of the node (ex. "1920x1080")
+	// on the dynamic Grid (se:screenResolution).
+	ScreenResolution string
+	// TimeZone is the time zone of the node (ex. "Asia/Tokyo") on the dynamic Grid (se:timeZone).
+	TimeZone string
+}
+
+// capabilities returns the configuration as the se: prefixed capabilities.
+func (c *SeleniumConfig) capabilities() map[string]any {
+	ret := map[string]any{}
+	if c.Name != "" {
+		ret["se:name"] = c.Name
+	}
+	if c.RecordVideo {
+		ret["se:recordVideo"] = true
+	}
+	if c.ScreenResolution != "" {
+		ret["se:screenResolution"] = c.ScreenResolution
+	}
+	if c.TimeZone != "" {
+		ret["se:timeZone"] = c.TimeZone
+	}
+	return ret
+}
+
+// SeleniumServer returns an instance of a Selenium standalone server WebDriver
+// launched from the JAR file. The sessions are opened at /wd/hub, which the
+// Selenium server keeps for the clients of the Selenium 3 hub.
+//
+// Provided Options will apply as default arguments for new pages.
+// A Browser Option must be provided to choose the browser, and the Selenium
+// Option specifies the se: prefixed capabilities. For instance:
+//
+//	driver := navigator.SeleniumServer("selenium-server.jar",
+//		navigator.Browser("firefox"),
+//		navigator.Selenium(navigator.SeleniumConfig{Name: "login test"}))
+//
+// The Selenium 4 server speaks the W3C WebDriver protocol only, so the
+// sessions are opened in the W3C dialect.
+//
+// See https://www.selenium.dev/documentation/grid/ for Selenium server details.
+func SeleniumServer(jarPath string, options ...Option) *WebDriver {
+	command := []string{executable("java"), "-jar", jarPath, "standalone", "--port", "{{.Port}}"}
+	driver := NewWebDriver("http://{{.Address}}"+session.SeleniumHubPath, command, options...)
+	driver.Dialect = session.DialectW3C
+	return driver
+}
+
+// NodeURI returns the URI of the Selenium Grid node on which the session of the page was granted.
+func (p *Page) NodeURI() (string, error) {
+	return p.NodeURIWithContext(context.Background())
+}
+
+// NodeURIWithContext returns the URI of the Selenium Grid node on which the session of the page was granted.
+func (p *Page) NodeURIWithContext(ctx context.Context) (string, error) {
+	uri, err := p.session.NodeURI(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve node URI: %w", err)
+	}
+	return uri, nil
+}
@@ -0,0 +1,50 @@
+package navigator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+func TestSeleniumServer(t *testing.T) {
+	options := []Option{Browser("firefox"), Selenium(SeleniumConfig{Name: "login test"})}
+	driver := SeleniumServer("selenium-server.jar", options...)
+	if got, want := driver.Dialect, session.DialectW3C; got != want {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	var payload map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("json.Decode() failed: unexpected error %v", err)
+		}
+		_, _ = io.WriteString(w, `{"value":{"sessionId":"s1","capabilities":{"browserName":"firefox"}}}`)
+	}))
+	defer ts.Close()
+	c := newConfig(options)
+	capabilities, err := c.capabilities()
+	if err != nil {
+		t.Fatalf("capabilities() failed: unexpected error %v", err)
+	}
+	if _, err := session.OpenWithDialect(context.Background(), ts.Client(), ts.URL, capabilities, false, driver.Dialect); err != nil {
+		t.Fatalf("OpenWithDialect() failed: unexpected error %v", err)
+	}
+	want := map[string]any{
+		"capabilities": map[string]any{
+			"alwaysMatch": map[string]any{
+				"acceptInsecureCerts": true,
+				"browserName":         "firefox",
+				"se:name":             "login test",
+			},
+		},
+	}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("want %+v, got %+v", want, payload)
+	}
+}
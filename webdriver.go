@@ -3,16 +3,28 @@ package navigator
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
 	"runtime"
+	"sync"
 
 	"github.com/ikawaha/navigator/webdriver"
+	"github.com/ikawaha/navigator/webdriver/session"
 )
 
 // A WebDriver controls a WebDriver process. This struct embeds webdriver.WebDriver,
 // which provides Start and Stop methods for starting and stopping the process.
+// New pages may be created by multiple goroutines concurrently.
 type WebDriver struct {
 	*webdriver.WebDriver
 	defaultConfig config
+
+	// driverPath and browser are used to check the versions of the driver and
+	// the browser before opening a session.
+	driverPath    string
+	browser       *browserSpec
+	mu            sync.Mutex
+	versionChecks map[string]error // key: browser path
 }
 
 // NewWebDriver returns an instance of a WebDriver specified by
@@ -34,11 +46,22 @@ type WebDriver struct {
 //	{{.Host}} - local address to bind to (usually 127.0.0.1)
 //	{{.Port}} - arbitrary free port on the local address
 //	{{.Address}} - {{.Host}}:{{.Port}}
+//	{{.LogPath}} - path given by the DriverLogPath Option
+//	{{.Verbose}} - true if the Verbose Option is provided
+//	{{.AllowedIPs}} - comma separated IPs given by the AllowedIPs Option
+//	{{.Fields.name}} - custom field given by the TemplateField Option
+//
+// The Port, PortRange and BindHost Options choose the address. If the process
+// exits before the service starts, for instance, because another process took
+// the port, the service is restarted on a new port unless the Port is fixed.
+//
+// An argument which is rendered to an empty string is dropped, e.g.
+// "{{if .Verbose}}--verbose{{end}}".
 //
-// Selenium JAR example:
+// Selenium JAR example (see also SeleniumServer):
 //
-//	command := []string{"java", "-jar", "selenium-server.jar", "-port", "{{.Port}}"}
-//	navigator.New("http://{{.Address}}/wd/hub", command)
+//	command := []string{"java", "-jar", "selenium-server.jar", "standalone", "--port", "{{.Port}}"}
+//	navigator.NewWebDriver("http://{{.Address}}/wd/hub", command)
 func NewWebDriver(url string, command []string, options ...Option) *WebDriver {
 	driver := webdriver.New(url, command)
 	c := newConfig(options)
@@ -51,6 +74,23 @@ func NewWebDriver(url string, command []string, options ...Option) *WebDriver {
 	if c.httpClient != nil {
 		driver.HTTPClient = c.httpClient
 	}
+	driver.Stdout = c.stdout
+	driver.Stderr = c.stderr
+	driver.LogFile = c.logFile
+	driver.LogPrefix = c.logPrefix
+	driver.LogPath = c.logPath
+	driver.Verbose = c.verbose
+	driver.Host = c.host
+	driver.Port = c.port
+	driver.MinPort = c.minPort
+	driver.MaxPort = c.maxPort
+	driver.AllowedIPs = c.allowedIPs
+	driver.Fields = c.fields
+	driver.Middlewares = c.middleware
+	driver.Logger = c.logger
+	if driver.Logger == nil && driver.Debug {
+		driver.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
 	return &WebDriver{
 		WebDriver:     driver,
 		defaultConfig: c,
@@ -62,33 +102,41 @@ func NewWebDriver(url string, command []string, options ...Option) *WebDriver {
 // Provided Options will apply as default arguments for new pages.
 // New pages will accept invalid SSL certificates by default. This
 // may be disabled using the RejectInvalidSSL Option.
+//
+// The chromedriver executable is looked up from the DriverPath Option, the
+// NAVIGATOR_CHROMEDRIVER environment variable and PATH in this order.
+// Before opening a session, the major versions of chromedriver and Chrome are
+// compared unless the SkipVersionCheck Option is provided.
 func ChromeDriver(options ...Option) *WebDriver {
-	var binaryName string
-	if runtime.GOOS == "windows" {
-		binaryName = "chromedriver.exe"
-	} else {
-		binaryName = "chromedriver"
-	}
-	command := []string{binaryName, "--port={{.Port}}"}
-	return NewWebDriver("http://{{.Address}}", command, options...)
+	return newDriver("chromedriver", chromeSpec, options, chromiumArgs...)
 }
 
-// EdgeDriver returns an instance of a EdgeDriver WebDriver.
+// chromiumArgs are the optional arguments of chromedriver and msedgedriver.
+var chromiumArgs = []string{
+	"{{if .LogPath}}--log-path={{.LogPath}}{{end}}",
+	"{{if .Verbose}}--verbose{{end}}",
+	"{{if .AllowedIPs}}--allowed-ips={{.AllowedIPs}}{{end}}",
+}
+
+// EdgeDriver returns an instance of a msedgedriver WebDriver which supports
+// Chromium based Microsoft Edge on all platforms. Use the Edge Option to
+// configure the browser.
 //
 // Provided Options will apply as default arguments for new pages.
 // New pages will accept invalid SSL certificates by default. This
 // may be disabled using the RejectInvalidSSL Option.
+//
+// See https://developer.microsoft.com/en-us/microsoft-edge/tools/webdriver/ for msedgedriver details.
+//
+// The msedgedriver executable is looked up from the DriverPath Option, the
+// NAVIGATOR_MSEDGEDRIVER environment variable and PATH in this order.
+// Before opening a session, the major versions of msedgedriver and Edge are
+// compared unless the SkipVersionCheck Option is provided.
+//
+// The error is always nil. It is kept for compatibility with the former
+// EdgeDriver, which supported only Windows.
 func EdgeDriver(options ...Option) (*WebDriver, error) {
-	var binaryName string
-	if runtime.GOOS == "windows" {
-		binaryName = "MicrosoftWebDriver.exe"
-	} else {
-		return nil, fmt.Errorf("not supported, windows only")
-	}
-	command := []string{binaryName, "--port={{.Port}}"}
-	// Using {{.Address}} means using 127.0.0.1
-	// But MicrosoftWebDriver only supports localhost, not 127.0.0.1
-	return NewWebDriver("http://localhost:{{.Port}}", command, options...), nil
+	return newDriver("msedgedriver", edgeSpec, options, chromiumArgs...), nil
 }
 
 // GeckoDriver returns an instance of a geckodriver WebDriver which supports
@@ -97,15 +145,72 @@ func EdgeDriver(options ...Option) (*WebDriver, error) {
 // Provided Options will apply as default arguments for new pages.
 //
 // See https://github.com/mozilla/geckodriver for geckodriver details.
+//
+// The geckodriver executable is looked up from the DriverPath Option, the
+// NAVIGATOR_GECKODRIVER environment variable and PATH in this order.
 func GeckoDriver(options ...Option) *WebDriver {
-	var binaryName string
+	return newDriver("geckodriver", nil, options, "--host={{.Host}}", "{{if .Verbose}}-v{{end}}")
+}
+
+// WebKitDriver returns an instance of a WebKitWebDriver WebDriver which
+// supports WebKitGTK based browsers like MiniBrowser and Epiphany.
+// WebKitWebDriver speaks the strict W3C WebDriver protocol, so the sessions
+// are opened in the W3C dialect. Use the WebKit Option to configure the browser.
+//
+// Provided Options will apply as default arguments for new pages.
+//
+// See https://webkitgtk.org/ for WebKitWebDriver details.
+//
+// The WebKitWebDriver executable is looked up from the DriverPath Option, the
+// NAVIGATOR_WEBKITWEBDRIVER environment variable and PATH in this order.
+func WebKitDriver(options ...Option) *WebDriver {
+	driver := newDriver("WebKitWebDriver", nil, options)
+	driver.Dialect = session.DialectW3C
+	return driver
+}
+
+// newDriver returns a WebDriver of the driver executable listening on --port.
+// If the browser is given, the versions of the driver and the browser are checked.
+func newDriver(name string, browser *browserSpec, options []Option, args ...string) *WebDriver {
+	c := newConfig(options)
+	path := lookupDriver(name, c.driverPath)
+	command := append([]string{path, "--port={{.Port}}"}, args...)
+	driver := NewWebDriver("http://{{.Address}}", command, options...)
+	driver.driverPath = path
+	driver.browser = browser
+	return driver
+}
+
+// checkVersion checks the versions of the driver and the browser of the page
+// configuration once per browser.
+func (w *WebDriver) checkVersion(ctx context.Context, c *config) error {
+	if w.browser == nil || c.skipVersionCheck {
+		return nil
+	}
+	browser := w.browser.lookup(c)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err, ok := w.versionChecks[browser]; ok {
+		return err
+	}
+	err := checkVersion(ctx, w.driverPath, browser, w.browser)
+	if ctx.Err() != nil {
+		// do not cache the result of the canceled check.
+		return err
+	}
+	if w.versionChecks == nil {
+		w.versionChecks = map[string]error{}
+	}
+	w.versionChecks[browser] = err
+	return err
+}
+
+// executable returns the executable name of the driver on the platform.
+func executable(name string) string {
 	if runtime.GOOS == "windows" {
-		binaryName = "geckodriver.exe"
-	} else {
-		binaryName = "geckodriver"
+		return name + ".exe"
 	}
-	command := []string{binaryName, "--port={{.Port}}"}
-	return NewWebDriver("http://{{.Address}}", command, options...)
+	return name
 }
 
 // NewPage returns a *Page that corresponds to a new WebDriver session.
@@ -148,9 +253,27 @@ func (w *WebDriver) NewPage(options ...Option) (*Page, error) {
 // http.DefaultClient if none was provided.
 func (w *WebDriver) NewPageWithContext(ctx context.Context, options ...Option) (*Page, error) {
 	c := newMergedConfig(w.defaultConfig, options)
-	s, err := w.OpenWithContext(ctx, c.capabilities())
+	capabilities, err := c.capabilities()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.checkVersion(ctx, &c); err != nil {
+		return nil, err
+	}
+	s, err := w.OpenWithContext(ctx, capabilities)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to WebDriver: %w", err)
 	}
-	return newPage(s), nil
+	p := newPage(s)
+	if err := c.emulate(ctx, p); err != nil {
+		_ = p.DestroyWithContext(ctx)
+		return nil, err
+	}
+	if ws := c.windowSize; ws != nil {
+		if err := p.SizeWithContext(ctx, ws.width, ws.height); err != nil {
+			_ = p.DestroyWithContext(ctx)
+			return nil, err
+		}
+	}
+	return p, nil
 }
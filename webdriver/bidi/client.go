@@ -0,0 +1,274 @@
+package bidi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Client is a WebDriver BiDi client.
+type Client struct {
+	conn *wsConn
+
+	mu       sync.Mutex
+	nextID   int64
+	pending  map[int64]chan message
+	handlers map[string][]*eventHandler
+	err      error
+
+	queue   *eventQueue
+	done    chan struct{}
+	closing sync.Once
+}
+
+// message is a command response or an event of the BiDi protocol.
+type message struct {
+	Type    string          `json:"type"`
+	ID      *int64          `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	Result  json.RawMessage `json:"result"`
+	Error   string          `json:"error"`
+	Message string          `json:"message"`
+}
+
+type command struct {
+	ID     int64  `json:"id"`
+	Method string `json:"method"`
+	Params any    `json:"params"`
+}
+
+// Error is an error response of the BiDi protocol.
+type Error struct {
+	Code    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Dial connects to the WebSocket URL of the BiDi session (the webSocketUrl
+// capability returned on the session creation).
+func Dial(ctx context.Context, url string) (*Client, error) {
+	conn, err := dialWebSocket(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		conn:     conn,
+		pending:  map[int64]chan message{},
+		handlers: map[string][]*eventHandler{},
+		queue:    newEventQueue(),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+	go c.dispatchLoop()
+	return c, nil
+}
+
+// Send sends the command with the params and unmarshals the result into the result.
+func (c *Client) Send(ctx context.Context, method string, params, result any) error {
+	if params == nil {
+		params = struct{}{}
+	}
+	ch := make(chan message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	b, err := json.Marshal(command{ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	if err := c.conn.WriteMessage(b); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return c.Err()
+	case m := <-ch:
+		if m.Type == "error" {
+			return &Error{Code: m.Error, Message: m.Message}
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(m.Result, result); err != nil {
+			return fmt.Errorf("unexpected result: %s", m.Result)
+		}
+		return nil
+	}
+}
+
+// Subscribe subscribes to the events (ex. "log.entryAdded").
+func (c *Client) Subscribe(ctx context.Context, events ...string) error {
+	return c.Send(ctx, "session.subscribe", map[string]any{
+		"events": events,
+	}, nil)
+}
+
+// Unsubscribe unsubscribes from the events.
+func (c *Client) Unsubscribe(ctx context.Context, events ...string) error {
+	return c.Send(ctx, "session.unsubscribe", map[string]any{
+		"events": events,
+	}, nil)
+}
+
+// eventHandler is a registered handler, whose pointer identifies it on removal.
+type eventHandler struct {
+	f func(params json.RawMessage)
+}
+
+// On registers the handler of the event and returns the function which
+// removes it. Handlers are called sequentially in the order the events were
+// received, on a goroutine other than the one reading the connection, so they
+// may send commands. Register handlers before subscribing to the event.
+func (c *Client) On(event string, handler func(params json.RawMessage)) (remove func()) {
+	h := &eventHandler{f: handler}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[event] = append(c.handlers[event], h)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// copy the handlers, which the dispatcher may be iterating over.
+		var handlers []*eventHandler
+		for _, v := range c.handlers[event] {
+			if v != h {
+				handlers = append(handlers, v)
+			}
+		}
+		c.handlers[event] = handlers
+	}
+}
+
+// Done returns a channel that is closed when the connection is closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the connection was closed, or nil if it is open.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	c.shutdown(errors.New("connection closed"))
+	return err
+}
+
+func (c *Client) shutdown(err error) {
+	c.closing.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		close(c.done)
+		c.queue.close()
+	})
+}
+
+func (c *Client) readLoop() {
+	for {
+		b, err := c.conn.ReadMessage()
+		if err != nil {
+			_ = c.conn.Close()
+			c.shutdown(fmt.Errorf("connection closed: %w", err))
+			return
+		}
+		var m message
+		if err := json.Unmarshal(b, &m); err != nil {
+			continue
+		}
+		switch {
+		case m.ID != nil:
+			c.mu.Lock()
+			ch, ok := c.pending[*m.ID]
+			c.mu.Unlock()
+			if ok {
+				ch <- m
+			}
+		case m.Type == "event":
+			c.queue.push(m)
+		}
+	}
+}
+
+func (c *Client) dispatchLoop() {
+	for {
+		m, ok := c.queue.pop()
+		if !ok {
+			return
+		}
+		c.mu.Lock()
+		handlers := c.handlers[m.Method]
+		c.mu.Unlock()
+		for _, h := range handlers {
+			h.f(m.Params)
+		}
+	}
+}
+
+// eventQueue is an unbounded queue of events.
+type eventQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []message
+	closed bool
+}
+
+func newEventQueue() *eventQueue {
+	q := &eventQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *eventQueue) push(m message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = append(q.items, m)
+	q.cond.Signal()
+}
+
+func (q *eventQueue) pop() (message, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return message{}, false
+	}
+	m := q.items[0]
+	q.items = q.items[1:]
+	return m, true
+}
+
+func (q *eventQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,163 @@
+package bidi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// standIn is a stand-in of the BiDi server of a web driver.
+func standIn(t *testing.T, events []string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() failed: unexpected error %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + acceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = rw.Flush()
+		ws := &wsConn{conn: conn, r: bufio.NewReader(rw)}
+		for {
+			b, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int64           `json:"id"`
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+			}
+			if err := json.Unmarshal(b, &cmd); err != nil {
+				t.Errorf("json.Unmarshal() failed: unexpected error %v", err)
+				return
+			}
+			switch cmd.Method {
+			case "session.subscribe":
+				resp, _ := json.Marshal(map[string]any{"type": "success", "id": cmd.ID, "result": map[string]any{}})
+				_ = ws.WriteMessage(resp)
+				for _, e := range events {
+					_ = ws.WriteMessage([]byte(e))
+				}
+			case "session.status":
+				// exceeds 125 bytes to exercise the extended payload length.
+				resp, _ := json.Marshal(map[string]any{"type": "success", "id": cmd.ID, "result": map[string]any{
+					"ready": true, "message": strings.Repeat("x", 200),
+				}})
+				_ = ws.WriteMessage(resp)
+			default:
+				resp, _ := json.Marshal(map[string]any{"type": "error", "id": cmd.ID, "error": "unknown command", "message": cmd.Method})
+				_ = ws.WriteMessage(resp)
+			}
+		}
+	}))
+}
+
+func TestClient(t *testing.T) {
+	events := []string{
+		`{"type":"event","method":"browsingContext.navigationStarted","params":{"context":"c1","navigation":"n1","timestamp":1,"url":"http://example.com/"}}`,
+		`{"type":"event","method":"network.beforeRequestSent","params":{"context":"c1","request":{"request":"r1","url":"http://example.com/","method":"GET","headers":[{"name":"Accept","value":{"type":"string","value":"*/*"}}]},"timestamp":2}}`,
+		`{"type":"event","method":"network.responseCompleted","params":{"context":"c1","request":{"request":"r1","url":"http://example.com/","method":"GET"},"response":{"url":"http://example.com/","status":200,"statusText":"OK","mimeType":"text/html"},"timestamp":3}}`,
+		`{"type":"event","method":"browsingContext.load","params":{"context":"c1","navigation":"n1","timestamp":4,"url":"http://example.com/"}}`,
+		`{"type":"event","method":"log.entryAdded","params":{"type":"console","level":"info","source":{"realm":"r"},"text":"hello","timestamp":5,"method":"log"}}`,
+	}
+	ts := standIn(t, events)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+"/session/s1")
+	if err != nil {
+		t.Fatalf("Dial() failed: unexpected error %v", err)
+	}
+	defer c.Close()
+
+	got := make(chan string, len(events))
+	c.OnNavigationStarted(func(e NavigationInfo) { got <- "navigationStarted " + e.URL })
+	c.OnBeforeRequestSent(func(e BeforeRequestSent) {
+		got <- "beforeRequestSent " + e.Request.Method + " " + e.Request.Headers[0].Value.Value
+	})
+	c.OnResponseCompleted(func(e ResponseCompleted) { got <- "responseCompleted " + e.Response.StatusText })
+	c.OnLoad(func(e NavigationInfo) { got <- "load " + e.Navigation })
+	c.OnLogEntryAdded(func(e LogEntry) { got <- "log " + e.Level + " " + e.Text })
+	remove := c.OnLoad(func(e NavigationInfo) { got <- "removed load " + e.Navigation })
+	remove()
+
+	if err := c.Subscribe(ctx, EventLogEntryAdded, EventBrowsingContextLoad); err != nil {
+		t.Fatalf("c.Subscribe() failed: unexpected error %v", err)
+	}
+	for _, want := range []string{
+		"navigationStarted http://example.com/",
+		"beforeRequestSent GET */*",
+		"responseCompleted OK",
+		"load n1",
+		"log info hello",
+	} {
+		select {
+		case g := <-got:
+			if g != want {
+				t.Errorf("want %q, got %q", want, g)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+
+	t.Run("result", func(t *testing.T) {
+		var result struct {
+			Ready   bool   `json:"ready"`
+			Message string `json:"message"`
+		}
+		if err := c.Send(ctx, "session.status", nil, &result); err != nil {
+			t.Fatalf("c.Send() failed: unexpected error %v", err)
+		}
+		if !result.Ready || len(result.Message) != 200 {
+			t.Errorf("unexpected result %+v", result)
+		}
+	})
+	t.Run("error response", func(t *testing.T) {
+		err := c.Send(ctx, "unknown.command", nil, nil)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("want *Error, got %v", err)
+		}
+		if got, want := e.Code, "unknown command"; got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	})
+	t.Run("closed", func(t *testing.T) {
+		if err := c.Close(); err != nil {
+			t.Errorf("c.Close() failed: unexpected error %v", err)
+		}
+		<-c.Done()
+		if err := c.Send(ctx, "session.status", nil, nil); err == nil {
+			t.Errorf("expected error, but nil")
+		}
+	})
+}
+
+func TestDial(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	for _, u := range []string{
+		"http://" + strings.TrimPrefix(ts.URL, "http://"),
+		"ws" + strings.TrimPrefix(ts.URL, "http"),
+	} {
+		if _, err := Dial(context.Background(), u); err == nil {
+			t.Errorf("Dial(%q): expected error, but nil", u)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// Package bidi is a WebDriver BiDi client over WebSocket.
+//
+// See: https://w3c.github.io/webdriver-bidi/
+package bidi
@@ -0,0 +1,128 @@
+package bidi
+
+import (
+	"encoding/json"
+)
+
+// Event names of the BiDi protocol.
+const (
+	EventLogEntryAdded                    = "log.entryAdded"
+	EventBrowsingContextLoad              = "browsingContext.load"
+	EventBrowsingContextNavigationStarted = "browsingContext.navigationStarted"
+	EventNetworkBeforeRequestSent         = "network.beforeRequestSent"
+	EventNetworkResponseCompleted         = "network.responseCompleted"
+)
+
+// Source is the source of a log entry.
+type Source struct {
+	Realm   string `json:"realm"`
+	Context string `json:"context"`
+}
+
+// LogEntry is the params of the log.entryAdded event.
+type LogEntry struct {
+	// Type is the type of the entry ("console" or "javascript").
+	Type string `json:"type"`
+	// Level is the level of the entry ("debug", "info", "warn" or "error").
+	Level     string `json:"level"`
+	Source    Source `json:"source"`
+	Text      string `json:"text"`
+	Timestamp int64  `json:"timestamp"`
+	// Method is the console method (ex. "log") of a console entry.
+	Method string `json:"method,omitempty"`
+}
+
+// NavigationInfo is the params of the browsingContext.load and
+// browsingContext.navigationStarted events.
+type NavigationInfo struct {
+	Context    string `json:"context"`
+	Navigation string `json:"navigation"`
+	Timestamp  int64  `json:"timestamp"`
+	URL        string `json:"url"`
+}
+
+// Header is an HTTP header of a request or a response.
+type Header struct {
+	Name  string `json:"name"`
+	Value struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"value"`
+}
+
+// RequestData describes a network request.
+type RequestData struct {
+	Request     string   `json:"request"`
+	URL         string   `json:"url"`
+	Method      string   `json:"method"`
+	Headers     []Header `json:"headers"`
+	HeadersSize int64    `json:"headersSize"`
+	BodySize    *int64   `json:"bodySize"`
+}
+
+// ResponseData describes a network response.
+type ResponseData struct {
+	URL           string   `json:"url"`
+	Protocol      string   `json:"protocol"`
+	Status        int      `json:"status"`
+	StatusText    string   `json:"statusText"`
+	FromCache     bool     `json:"fromCache"`
+	Headers       []Header `json:"headers"`
+	MimeType      string   `json:"mimeType"`
+	BytesReceived int64    `json:"bytesReceived"`
+}
+
+// BeforeRequestSent is the params of the network.beforeRequestSent event.
+type BeforeRequestSent struct {
+	Context       string      `json:"context"`
+	Navigation    string      `json:"navigation"`
+	RedirectCount int         `json:"redirectCount"`
+	Request       RequestData `json:"request"`
+	Timestamp     int64       `json:"timestamp"`
+	IsBlocked     bool        `json:"isBlocked"`
+}
+
+// ResponseCompleted is the params of the network.responseCompleted event.
+type ResponseCompleted struct {
+	Context       string       `json:"context"`
+	Navigation    string       `json:"navigation"`
+	RedirectCount int          `json:"redirectCount"`
+	Request       RequestData  `json:"request"`
+	Response      ResponseData `json:"response"`
+	Timestamp     int64        `json:"timestamp"`
+}
+
+func on[T any](c *Client, event string, handler func(T)) (remove func()) {
+	return c.On(event, func(params json.RawMessage) {
+		var v T
+		if err := json.Unmarshal(params, &v); err != nil {
+			return
+		}
+		handler(v)
+	})
+}
+
+// OnLogEntryAdded registers the handler of the log.entryAdded event and returns the function which removes it.
+func (c *Client) OnLogEntryAdded(handler func(LogEntry)) (remove func()) {
+	return on(c, EventLogEntryAdded, handler)
+}
+
+// OnLoad registers the handler of the browsingContext.load event and returns the function which removes it.
+func (c *Client) OnLoad(handler func(NavigationInfo)) (remove func()) {
+	return on(c, EventBrowsingContextLoad, handler)
+}
+
+// OnNavigationStarted registers the handler of the browsingContext.navigationStarted event and returns the function which removes it.
+func (c *Client) OnNavigationStarted(handler func(NavigationInfo)) (remove func()) {
+	return on(c, EventBrowsingContextNavigationStarted, handler)
+}
+
+// OnBeforeRequestSent registers the handler of the network.beforeRequestSent event and returns the function which removes it.
+func (c *Client) OnBeforeRequestSent(handler func(BeforeRequestSent)) (remove func()) {
+	return on(c, EventNetworkBeforeRequestSent, handler)
+}
+
+// OnResponseCompleted registers the handler of the network.responseCompleted event and returns the function which removes it.
+func (c *Client) OnResponseCompleted(handler func(ResponseCompleted)) (remove func()) {
+	return on(c, EventNetworkResponseCompleted, handler)
+}
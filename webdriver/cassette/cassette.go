@@ -0,0 +1,49 @@
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// A Cassette is the recorded traffic of the sessions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// An Interaction is a recorded request and its response.
+type Interaction struct {
+	// Method and Path are the HTTP method and the URL path of the request.
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Request is the request body.
+	Request string `json:"request,omitempty"`
+	// Status and Response are the HTTP status and the body of the response.
+	Status   int    `json:"status"`
+	Response string `json:"response"`
+}
+
+// Load reads the cassette from the file.
+func Load(filename string) (*Cassette, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", filename, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to the file.
+func (c *Cassette) Save(filename string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.WriteFile(filename, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
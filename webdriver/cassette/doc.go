@@ -0,0 +1,28 @@
+// Package cassette records the traffic of web driver sessions to cassettes
+// and replays it without a browser.
+//
+// A Recorder is an http.RoundTripper given to the sessions by the HTTPClient
+// Option. In the record mode it sends the requests to the web driver and
+// records the responses:
+//
+//	rec := cassette.NewRecorder(nil)
+//	driver := navigator.ChromeDriver(navigator.HTTPClient(&http.Client{Transport: rec}))
+//	...
+//	err := rec.Save("testdata/login.json")
+//
+// In the replay mode it serves the recorded responses. The web driver process
+// is still started, but the browser is not, because the sessions are never
+// opened on the web driver:
+//
+//	c, err := cassette.Load("testdata/login.json")
+//	rec := cassette.NewReplayer(c, cassette.SessionIDs, cassette.ElementIDs)
+//	driver := navigator.ChromeDriver(navigator.HTTPClient(&http.Client{Transport: rec}), navigator.SkipVersionCheck)
+//
+// To replay without the web driver installed, open the session on the
+// webdriver/session package with the replaying client. The requests never
+// leave the client, so any URL serves as the URL of the service:
+//
+//	c, err := cassette.Load("testdata/login.json")
+//	client := &http.Client{Transport: cassette.NewReplayer(c, cassette.SessionIDs, cassette.ElementIDs)}
+//	s, err := session.OpenWithClient(ctx, client, "http://replay.invalid", nil, false)
+package cassette
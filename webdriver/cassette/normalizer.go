@@ -0,0 +1,33 @@
+package cassette
+
+import (
+	"regexp"
+)
+
+// A Normalizer rewrites the path or the body of a request before it is
+// matched with the recorded requests, so that the requests which differ only
+// in the values such as IDs are matched.
+type Normalizer func(s string) string
+
+var (
+	sessionPattern     = regexp.MustCompile(`/session/[^/]+`)
+	elementPathPattern = regexp.MustCompile(`/(element|equals)/[^/]+`)
+	elementRefPattern  = regexp.MustCompile(`"(ELEMENT|element-6066-11e4-a52e-4f735466cecf)":\s*"[^"]*"`)
+)
+
+// SessionIDs replaces the session IDs in the paths with a placeholder.
+func SessionIDs(s string) string {
+	return sessionPattern.ReplaceAllString(s, "/session/{session}")
+}
+
+// ElementIDs replaces the element IDs in the paths and the element references
+// in the bodies with placeholders.
+func ElementIDs(s string) string {
+	s = elementPathPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if m == "/element/active" {
+			return m
+		}
+		return elementPathPattern.ReplaceAllString(m, "/$1/{element}")
+	})
+	return elementRefPattern.ReplaceAllString(s, `"$1":"{element}"`)
+}
@@ -0,0 +1,145 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// ErrNoInteraction is returned by a replaying Recorder when no recorded
+// interaction matches the request.
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// A Recorder is an http.RoundTripper which records the traffic to a cassette,
+// or replays the traffic recorded in a cassette. It is safe for concurrent use
+// by multiple goroutines.
+type Recorder struct {
+	// Transport sends the requests in the record mode. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+	// Normalizers rewrite the paths and the bodies of the requests before they
+	// are matched in the replay mode.
+	Normalizers []Normalizer
+
+	replay   bool
+	mu       sync.Mutex // guards cassette and used
+	cassette *Cassette
+	used     []bool
+}
+
+// NewRecorder returns a Recorder which sends the requests with the transport
+// and records them to a new cassette.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	return &Recorder{
+		Transport: transport,
+		cassette:  &Cassette{},
+	}
+}
+
+// NewReplayer returns a Recorder which serves the responses recorded in the
+// cassette. The requests are matched with the recorded requests by the method,
+// the path and the body, in the recorded order. A nil cassette is empty.
+func NewReplayer(c *Cassette, normalizers ...Normalizer) *Recorder {
+	if c == nil {
+		c = &Cassette{}
+	}
+	return &Recorder{
+		Normalizers: normalizers,
+		replay:      true,
+		cassette:    c,
+		used:        make([]bool, len(c.Interactions)),
+	}
+}
+
+// Cassette returns the cassette of the recorder.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Save writes the cassette of the recorder to the file.
+func (r *Recorder) Save(filename string) error {
+	return r.Cassette().Save(filename)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request: %w", err)
+		}
+		body = b
+	}
+	if r.replay {
+		return r.play(req, body)
+	}
+	return r.record(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		Request:  string(body),
+		Status:   resp.StatusCode,
+		Response: string(b),
+	})
+	r.mu.Unlock()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	return resp, nil
+}
+
+func (r *Recorder) play(req *http.Request, body []byte) (*http.Response, error) {
+	path, request := r.normalize(req.URL.Path), r.normalize(string(body))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, v := range r.cassette.Interactions {
+		if r.used[i] || v.Method != req.Method || r.normalize(v.Path) != path || r.normalize(v.Request) != request {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        strconv.Itoa(v.Status) + " " + http.StatusText(v.Status),
+			StatusCode:    v.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+			Body:          io.NopCloser(bytes.NewReader([]byte(v.Response))),
+			ContentLength: int64(len(v.Response)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s %s", ErrNoInteraction, req.Method, req.URL.Path, body)
+}
+
+func (r *Recorder) normalize(s string) string {
+	for _, n := range r.Normalizers {
+		s = n(s)
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+	"github.com/ikawaha/navigator/webdriver/webdrivertest"
+)
+
+// browse runs the scenario and returns the title and the text of the link.
+func browse(ctx context.Context, client *http.Client, url string) (title, text string, err error) {
+	s, err := session.OpenWithClient(ctx, client, url, nil, false)
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.SetURL(ctx, "http://example.com/"); err != nil {
+		return "", "", err
+	}
+	if title, err = s.GetTitle(ctx); err != nil {
+		return "", "", err
+	}
+	e, err := s.GetElement(ctx, session.Selector{Using: "link text", Value: "Next"})
+	if err != nil {
+		return "", "", err
+	}
+	if text, err = e.GetText(ctx); err != nil {
+		return "", "", err
+	}
+	return title, text, s.Delete(ctx)
+}
+
+func TestRecorder(t *testing.T) {
+	srv := webdrivertest.NewServer()
+	srv.Handle("http://example.com/", &webdrivertest.Document{
+		Title:    "Example",
+		Elements: []*webdrivertest.Element{{Tag: "a", Text: "Next"}},
+	})
+	ctx := context.Background()
+
+	rec := NewRecorder(nil)
+	title, text, err := browse(ctx, &http.Client{Transport: rec}, srv.URL)
+	if err != nil {
+		t.Fatalf("browse() failed: unexpected error %v", err)
+	}
+	srv.Close()
+	filename := filepath.Join(t.TempDir(), "cassette.json")
+	if err := rec.Save(filename); err != nil {
+		t.Fatalf("Save() failed: unexpected error %v", err)
+	}
+
+	c, err := Load(filename)
+	if err != nil {
+		t.Fatalf("Load() failed: unexpected error %v", err)
+	}
+	if got, want := len(c.Interactions), 6; got != want {
+		t.Fatalf("want %d interactions, got %d", want, got)
+	}
+	// the server is closed, and the responses are replayed.
+	replayed, replayedText, err := browse(ctx, &http.Client{Transport: NewReplayer(c)}, "http://replay.invalid")
+	if err != nil {
+		t.Fatalf("browse() failed: unexpected error %v", err)
+	}
+	if replayed != title || replayedText != text {
+		t.Errorf("want %q and %q, got %q and %q", title, text, replayed, replayedText)
+	}
+
+	t.Run("exhausted", func(t *testing.T) {
+		client := &http.Client{Transport: NewReplayer(c)}
+		if _, _, err := browse(ctx, client, "http://replay.invalid"); err != nil {
+			t.Fatalf("browse() failed: unexpected error %v", err)
+		}
+		if _, _, err := browse(ctx, client, "http://replay.invalid"); !errors.Is(err, ErrNoInteraction) {
+			t.Errorf("want %v, got %v", ErrNoInteraction, err)
+		}
+	})
+	t.Run("nil cassette", func(t *testing.T) {
+		client := &http.Client{Transport: NewReplayer(nil)}
+		if _, _, err := browse(ctx, client, "http://replay.invalid"); !errors.Is(err, ErrNoInteraction) {
+			t.Errorf("want %v, got %v", ErrNoInteraction, err)
+		}
+	})
+}
+
+func TestNormalizer(t *testing.T) {
+	testdata := []struct {
+		name       string
+		normalizer Normalizer
+		input      string
+		want       string
+	}{
+		{name: "session path", normalizer: SessionIDs, input: "/session/abc/element/1/click", want: "/session/{session}/element/1/click"},
+		{name: "element path", normalizer: ElementIDs, input: "/session/abc/element/1/click", want: "/session/abc/element/{element}/click"},
+		{name: "equals path", normalizer: ElementIDs, input: "/session/abc/element/1/equals/2", want: "/session/abc/element/{element}/equals/{element}"},
+		{name: "active element", normalizer: ElementIDs, input: "/session/abc/element/active", want: "/session/abc/element/active"},
+		{name: "element reference", normalizer: ElementIDs, input: `{"args":[{"ELEMENT": "x"}]}`, want: `{"args":[{"ELEMENT":"{element}"}]}`},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.normalizer(tt.input); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	c := &Cassette{Interactions: []Interaction{
+		{Method: http.MethodGet, Path: "/session/recorded/element/1/text", Status: http.StatusOK, Response: `{"value":"recorded"}`},
+	}}
+	req, _ := http.NewRequest(http.MethodGet, "http://replay.invalid/session/other/element/2/text", nil)
+	if _, err := NewReplayer(c).RoundTrip(req); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("want %v, got %v", ErrNoInteraction, err)
+	}
+	resp, err := NewReplayer(c, SessionIDs, ElementIDs).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() failed: unexpected error %v", err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if got, want := string(b), `{"value":"recorded"}`; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
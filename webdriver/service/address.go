@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// addressInfo is the data of the URL and command templates.
+type addressInfo struct {
+	Address    string
+	Host       string
+	Port       string
+	LogPath    string
+	Verbose    bool
+	AllowedIPs string
+	Fields     map[string]string
+}
+
+// address returns the address the service binds to.
+func (s *Service) address(ctx context.Context) (addressInfo, error) {
+	host := s.Host
+	if host == "" {
+		host = "localhost"
+	}
+	var (
+		address addressInfo
+		err     error
+	)
+	switch {
+	case s.Port != 0:
+		address = newAddressInfo(host, strconv.Itoa(s.Port))
+	case s.MinPort != 0 || s.MaxPort != 0:
+		address, err = getFreeAddressInRange(ctx, host, s.MinPort, s.MaxPort)
+	default:
+		address, err = getFreeAddress(ctx, host)
+	}
+	if err != nil {
+		return addressInfo{}, err
+	}
+	address.LogPath = s.LogPath
+	address.Verbose = s.Verbose
+	address.AllowedIPs = strings.Join(s.AllowedIPs, ",")
+	address.Fields = s.Fields
+	return address, nil
+}
+
+func newAddressInfo(host, port string) addressInfo {
+	return addressInfo{
+		Address: net.JoinHostPort(host, port),
+		Host:    host,
+		Port:    port,
+	}
+}
+
+// getFreeAddress returns a free port of the host. The port may be taken by
+// another process before the service binds it; Boot retries in that case.
+func getFreeAddress(ctx context.Context, host string) (addressInfo, error) {
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return addressInfo{}, err
+	}
+	defer l.Close()
+
+	address := l.Addr().String()
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return addressInfo{}, err
+	}
+	return addressInfo{
+		Address: address,
+		Host:    host,
+		Port:    port,
+	}, nil
+}
+
+// getFreeAddressInRange returns a free port between minPort and maxPort of the host.
+// It starts from a random port so that parallel jobs rarely choose the same port.
+func getFreeAddressInRange(ctx context.Context, host string, minPort, maxPort int) (addressInfo, error) {
+	if minPort <= 0 || maxPort > 65535 || minPort > maxPort {
+		return addressInfo{}, fmt.Errorf("invalid port range: %d-%d", minPort, maxPort)
+	}
+	n := maxPort - minPort + 1
+	offset := rand.Intn(n)
+	var lc net.ListenConfig
+	for i := 0; i < n; i++ {
+		port := strconv.Itoa(minPort + (offset+i)%n)
+		l, err := lc.Listen(ctx, "tcp", net.JoinHostPort(host, port))
+		if err != nil {
+			if ctx.Err() != nil {
+				return addressInfo{}, ctx.Err()
+			}
+			continue
+		}
+		l.Close()
+		return newAddressInfo(host, port), nil
+	}
+	return addressInfo{}, errors.New("no free port in the range")
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestService_address(t *testing.T) {
+	ctx := context.Background()
+	t.Run("fixed port", func(t *testing.T) {
+		s := New("", nil)
+		s.Host = "127.0.0.1"
+		s.Port = 4444
+		got, err := s.address(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := "127.0.0.1:4444"; got.Address != want {
+			t.Errorf("want %q, got %q", want, got.Address)
+		}
+	})
+	t.Run("port range", func(t *testing.T) {
+		// take a port and let the range include only the taken port and the next one.
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("net.Listen() failed: unexpected error %v", err)
+		}
+		defer l.Close()
+		taken := l.Addr().(*net.TCPAddr).Port
+		s := New("", nil)
+		s.Host = "127.0.0.1"
+		s.MinPort = taken
+		s.MaxPort = taken + 1
+		for i := 0; i < 10; i++ {
+			got, err := s.address(ctx)
+			if err != nil {
+				t.Skipf("the next port is also taken: %v", err)
+			}
+			if want := strconv.Itoa(taken + 1); got.Port != want {
+				t.Fatalf("want %q, got %q", want, got.Port)
+			}
+		}
+	})
+	t.Run("invalid port range", func(t *testing.T) {
+		s := New("", nil)
+		s.MinPort = 5000
+		s.MaxPort = 4000
+		if _, err := s.address(ctx); err == nil {
+			t.Errorf("expected error, but nil")
+		}
+	})
+	t.Run("allowed IPs", func(t *testing.T) {
+		s := New("", nil)
+		s.AllowedIPs = []string{"10.0.0.1", "10.0.0.2"}
+		got, err := s.address(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := "10.0.0.1,10.0.0.2"; got.AllowedIPs != want {
+			t.Errorf("want %q, got %q", want, got.AllowedIPs)
+		}
+	})
+}
@@ -9,7 +9,7 @@ import (
 )
 
 func buildURL(urlT string, address addressInfo) (string, error) {
-	tpl, err := template.New("URL").Parse(urlT)
+	tpl, err := template.New("URL").Option("missingkey=error").Parse(urlT)
 	if err != nil {
 		return "", err
 	}
@@ -26,7 +26,7 @@ func buildCommand(ctx context.Context, commandT []string, address addressInfo) (
 	}
 	var command []string
 	for _, v := range commandT {
-		tpl, err := template.New("command").Parse(v)
+		tpl, err := template.New("command").Option("missingkey=error").Parse(v)
 		if err != nil {
 			return nil, err
 		}
@@ -34,7 +34,14 @@ func buildCommand(ctx context.Context, commandT []string, address addressInfo) (
 		if err := tpl.Execute(&b, address); err != nil {
 			return nil, err
 		}
+		if v != "" && b.Len() == 0 {
+			// drop the optional argument (ex. "{{if .Verbose}}--verbose{{end}}").
+			continue
+		}
 		command = append(command, b.String())
 	}
+	if len(command) == 0 {
+		return nil, errors.New("empty command")
+	}
 	return exec.CommandContext(ctx, command[0], command[1:]...), nil
 }
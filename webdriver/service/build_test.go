@@ -120,6 +120,48 @@ func Test_buildCommand(t *testing.T) {
 			want:    "abc def",
 			wantErr: false,
 		},
+		{
+			name: "optional arguments",
+			args: args{
+				commandT: []string{"abc", "{{if .LogPath}}--log-path={{.LogPath}}{{end}}", "{{if .Verbose}}--verbose{{end}}"},
+				address: addressInfo{
+					Address: "address",
+					Host:    "host",
+					Port:    "8080",
+					LogPath: "driver.log",
+				},
+			},
+			want:    "abc --log-path=driver.log",
+			wantErr: false,
+		},
+		{
+			name: "custom fields",
+			args: args{
+				commandT: []string{"abc", "--allowed-ips={{.AllowedIPs}}", "--token={{.Fields.token}}"},
+				address: addressInfo{
+					Address:    "address",
+					Host:       "host",
+					Port:       "8080",
+					AllowedIPs: "10.0.0.1,10.0.0.2",
+					Fields:     map[string]string{"token": "secret"},
+				},
+			},
+			want:    "abc --allowed-ips=10.0.0.1,10.0.0.2 --token=secret",
+			wantErr: false,
+		},
+		{
+			name: "unknown custom field",
+			args: args{
+				commandT: []string{"abc", "--token={{.Fields.token}}"},
+				address: addressInfo{
+					Address: "address",
+					Host:    "host",
+					Port:    "8080",
+				},
+			},
+			want:    "",
+			wantErr: true,
+		},
 		{
 			name: "empty command",
 			args: args{
@@ -133,6 +175,19 @@ func Test_buildCommand(t *testing.T) {
 			want:    "",
 			wantErr: true,
 		},
+		{
+			name: "all arguments empty",
+			args: args{
+				commandT: []string{"{{if .LogPath}}{{.LogPath}}{{end}}", "{{if .Verbose}}--verbose{{end}}"},
+				address: addressInfo{
+					Address: "address",
+					Host:    "host",
+					Port:    "8080",
+				},
+			},
+			want:    "",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
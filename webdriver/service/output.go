@@ -0,0 +1,59 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// lineWriter writes each line with the prefix to the writer. Incomplete
+// lines are buffered until the newline is written or the writer is flushed.
+type lineWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func newLineWriter(w io.Writer, prefix string) *lineWriter {
+	return &lineWriter{w: w, prefix: prefix}
+}
+
+// Write implements io.Writer.
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	lw.buf = append(lw.buf, p...)
+	for {
+		i := bytes.IndexByte(lw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := lw.writeLine(lw.buf[:i+1]); err != nil {
+			return len(p), err
+		}
+		lw.buf = lw.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered incomplete line.
+func (lw *lineWriter) Flush() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if len(lw.buf) == 0 {
+		return nil
+	}
+	line := append(lw.buf, '\n')
+	lw.buf = nil
+	return lw.writeLine(line)
+}
+
+func (lw *lineWriter) writeLine(line []byte) error {
+	b := make([]byte, 0, len(lw.prefix)+len(line))
+	b = append(append(b, lw.prefix...), line...)
+	_, err := lw.w.Write(b)
+	return err
+}
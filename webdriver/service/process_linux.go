@@ -0,0 +1,30 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group, so that the
+// browser processes spawned by the driver are signaled together with it.
+func setProcessGroup(command *exec.Cmd) {
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.Setpgid = true
+	// kill the process group when the context of the command is done.
+	command.Cancel = func() error {
+		return signalProcess(command.Process, syscall.SIGKILL)
+	}
+}
+
+// signalProcess sends the signal to the process group of the process.
+func signalProcess(process *os.Process, sig syscall.Signal) error {
+	err := syscall.Kill(-process.Pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package service
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// setProcessGroup does nothing on the platform.
+func setProcessGroup(*exec.Cmd) {}
+
+// signalProcess sends the signal to the process. Windows does not support
+// signals other than kill.
+func signalProcess(process *os.Process, sig syscall.Signal) error {
+	if runtime.GOOS == "windows" {
+		return process.Kill()
+	}
+	return process.Signal(sig)
+}
@@ -1,16 +1,15 @@
 package service
 
 import (
-	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"log"
-	"net"
+	"log/slog"
 	"net/http"
+	"os"
 	"os/exec"
-	"runtime"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -18,13 +17,59 @@ import (
 
 // Service represents a web driver service.
 type Service struct {
+	// Stdout and Stderr receive the output of the driver process line by line.
+	// If they are nil, the output is logged by the Logger, or by a text handler
+	// to stderr in debug mode.
+	Stdout io.Writer
+	Stderr io.Writer
+	// LogFile is the path of the file the output of the driver process is appended to.
+	LogFile string
+	// Prefix is prepended to each line of the output. It is a template
+	// like the URL (ex. "[chromedriver:{{.Port}}] ").
+	Prefix string
+	// LogPath and Verbose are given to the command template as {{.LogPath}}
+	// and {{.Verbose}} (ex. "{{if .Verbose}}--verbose{{end}}").
+	LogPath string
+	Verbose bool
+
+	// Host is the host the service binds to. The default is "localhost".
+	Host string
+	// Port is the fixed port of the service. If zero, a free port between
+	// MinPort and MaxPort, or any free port if they are zero, is used.
+	Port    int
+	MinPort int
+	MaxPort int
+	// AllowedIPs are the remote IPs allowed to connect to the service, given
+	// to the command template as comma separated {{.AllowedIPs}}.
+	AllowedIPs []string
+	// Fields are the custom fields given to the URL and command templates
+	// as {{.Fields.name}}.
+	Fields map[string]string
+	// Logger receives the structured records of starting the service and the
+	// output of the driver process. If nil, the records are logged by a text
+	// handler to stderr in debug mode, and discarded otherwise.
+	Logger *slog.Logger
+
 	mu       sync.Mutex
 	urlT     string   // url template eg. "http://localhost:{{.Port}}"
 	commandT []string // command template eg. ["chromedriver", "--port={{.Port}}"]
 	baseURL  string
 	command  *exec.Cmd
+	logFile  *os.File
+	outputs  []*lineWriter
+	done     chan struct{}
+	err      error
+	stopping bool
 }
 
+var (
+	// ErrStopped is reported by Err when the service was stopped by Stop.
+	ErrStopped = errors.New("service stopped")
+
+	// ErrExited is reported by Err when the service exited unexpectedly.
+	ErrExited = errors.New("service exited")
+)
+
 // New creates new web driver service.
 func New(urlT string, commandT []string) *Service {
 	return &Service{
@@ -35,9 +80,28 @@ func New(urlT string, commandT []string) *Service {
 
 // URL returns the base URL of the service.
 func (s *Service) URL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.baseURL
 }
 
+// Done returns a channel which is closed when the process of the service
+// started last exits. It returns nil if the service has never been started.
+func (s *Service) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done
+}
+
+// Err returns nil while the process of the service is running or has never
+// been started. After Done is closed, it returns ErrStopped if the service
+// was stopped by Stop, or an error wrapping ErrExited otherwise.
+func (s *Service) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
 // Start starts the service.
 func (s *Service) Start(ctx context.Context, debug bool) error {
 	s.mu.Lock()
@@ -47,7 +111,7 @@ func (s *Service) Start(ctx context.Context, debug bool) error {
 		return errors.New("already running")
 	}
 
-	address, err := getFreeAddress(ctx)
+	address, err := s.address(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to locate a free port: %w", err)
 	}
@@ -62,122 +126,219 @@ func (s *Service) Start(ctx context.Context, debug bool) error {
 	if err != nil {
 		return fmt.Errorf("failed to parse command: %w", err)
 	}
-	if debug {
-		log.Print(command.String())
-		stdout, err := command.StdoutPipe()
-		if err != nil {
-			return err
-		}
-		go func(ctx context.Context) {
-			defer stdout.Close()
-			in := bufio.NewScanner(stdout)
-		loop:
-			for {
-				select {
-				case <-ctx.Done():
-					break loop
-				default:
-					if in.Scan() {
-						log.Print(in.Text())
-					}
-				}
-			}
-		}(ctx)
+	logger := s.logger(debug)
+	if logger != nil {
+		logger.Info("starting service", "command", command.String(), "url", url)
+	}
+	setProcessGroup(command)
+	if err := s.connectOutput(command, address, logger); err != nil {
+		return err
 	}
 	if err := command.Start(); err != nil {
+		s.closeOutput()
 		err = fmt.Errorf("failed to run command: %w", err)
-		if debug {
-			log.Print("ERROR: " + err.Error())
+		if logger != nil {
+			logger.Error("failed to start service", "command", command.String(), "error", err)
 		}
 		return err
 	}
 	s.command = command
+	s.done = make(chan struct{})
+	s.err = nil
+	s.stopping = false
+	go s.wait(command, s.done)
 	return nil
 }
 
-// Stop stops the service.
-func (s *Service) Stop() error {
+// wait waits for the process to exit and releases the service so that it can be started again.
+func (s *Service) wait(command *exec.Cmd, done chan struct{}) {
+	err := command.Wait()
+	// reap the browser processes left by the driver.
+	_ = signalProcess(command.Process, syscall.SIGKILL)
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	if s.command == nil {
-		return errors.New("already stopped")
-	}
-	switch runtime.GOOS {
-	case "windows":
-		if err := s.command.Process.Kill(); err != nil {
-			return fmt.Errorf("failed to stop command: %w", err)
-		}
+	switch {
+	case s.stopping:
+		s.err = ErrStopped
+	case err != nil:
+		s.err = fmt.Errorf("%w: %w", ErrExited, err)
 	default:
-		if err := s.command.Process.Signal(syscall.SIGTERM); err != nil {
-			return fmt.Errorf("failed to stop command: %w", err)
-		}
+		s.err = ErrExited
 	}
-	_ = s.command.Wait()
+	s.closeOutput()
 	s.command = nil
 	s.baseURL = ""
+	close(done)
+}
+
+// outputWaitDelay bounds the wait for the output of the browser processes
+// which inherit the output of the driver process.
+const outputWaitDelay = time.Second
+
+// connectOutput connects the stdout and stderr of the command to the sinks.
+func (s *Service) connectOutput(command *exec.Cmd, address addressInfo, logger *slog.Logger) error {
+	prefix, err := buildURL(s.Prefix, address)
+	if err != nil {
+		return fmt.Errorf("failed to parse prefix: %w", err)
+	}
+	stdout, stderr := s.Stdout, s.Stderr
+	if logger != nil && stdout == nil {
+		stdout = logWriter{logger: logger, stream: "stdout", port: address.Port}
+	}
+	if logger != nil && stderr == nil {
+		stderr = logWriter{logger: logger, stream: "stderr", port: address.Port}
+	}
+	if s.LogFile != "" {
+		f, err := os.OpenFile(s.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		s.logFile = f
+		stdout = joinWriters(stdout, f)
+		stderr = joinWriters(stderr, f)
+	}
+	if stdout != nil {
+		w := newLineWriter(stdout, prefix)
+		s.outputs = append(s.outputs, w)
+		command.Stdout = w
+	}
+	if stderr != nil {
+		w := newLineWriter(stderr, prefix)
+		s.outputs = append(s.outputs, w)
+		command.Stderr = w
+	}
+	command.WaitDelay = outputWaitDelay
 	return nil
 }
 
-type addressInfo struct {
-	Address string
-	Host    string
-	Port    string
+// closeOutput flushes the incomplete lines and closes the log file.
+func (s *Service) closeOutput() {
+	for _, w := range s.outputs {
+		_ = w.Flush()
+	}
+	s.outputs = nil
+	if s.logFile != nil {
+		_ = s.logFile.Close()
+		s.logFile = nil
+	}
 }
 
-func getFreeAddress(ctx context.Context) (addressInfo, error) {
-	var lc net.ListenConfig
-	l, err := lc.Listen(ctx, "tcp", "localhost:0")
-	if err != nil {
-		return addressInfo{}, err
+func joinWriters(w io.Writer, f *os.File) io.Writer {
+	if w == nil {
+		return f
 	}
-	defer l.Close()
+	return io.MultiWriter(w, f)
+}
 
-	address := l.Addr().String()
-	host, port, err := net.SplitHostPort(address)
-	if err != nil {
-		return addressInfo{}, err
+// logWriter writes each output line as a record of the logger.
+type logWriter struct {
+	logger *slog.Logger
+	stream string
+	port   string
+}
+
+func (w logWriter) Write(p []byte) (int, error) {
+	w.logger.Debug("driver output", "stream", w.stream, "port", w.port, "line", strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// logger returns the Logger of the service, or the text handler to stderr in
+// debug mode, or nil.
+func (s *Service) logger(debug bool) *slog.Logger {
+	if s.Logger != nil {
+		return s.Logger
 	}
-	return addressInfo{
-		Address: address,
-		Host:    host,
-		Port:    port,
-	}, nil
+	if debug {
+		return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return nil
+}
+
+// Stop stops the service.
+// On Linux, the signals are sent to the process group of the driver, which
+// includes the browser processes spawned by it. Stop sends SIGTERM and waits
+// for the process to exit until the context is done, then sends SIGKILL.
+func (s *Service) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.command == nil {
+		s.mu.Unlock()
+		return errors.New("already stopped")
+	}
+	s.stopping = true
+	process, done := s.command.Process, s.done
+	s.mu.Unlock()
+
+	if err := signalProcess(process, syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to stop command: %w", err)
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	if err := signalProcess(process, syscall.SIGKILL); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to kill command: %w", err)
+	}
+	<-done
+	return nil
 }
 
 const bootWait = 500 * time.Millisecond
 
-// WaitForBoot waits until the service starts.
+// WaitForBoot waits until the service starts. It fails without waiting for
+// the timeout if the process exits, for instance, because it failed to bind the port.
 func (s *Service) WaitForBoot(ctx context.Context, timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
-	wakeup := make(chan struct{})
-	go func(ctx context.Context) {
-		up := s.checkStatus(ctx)
-		for !up {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				time.Sleep(bootWait)
-				up = s.checkStatus(ctx)
-			}
+	done := s.Done()
+	for !s.checkStatus(ctx) {
+		select {
+		case <-ctx.Done():
+			return errors.New("failed to start before timeout")
+		case <-done:
+			return fmt.Errorf("failed to start: %w", s.Err())
+		case <-time.After(bootWait):
 		}
-		wakeup <- struct{}{}
-	}(ctx)
-	select {
-	case <-ctx.Done():
-		return errors.New("failed to start before timeout")
-	case <-wakeup:
-		return nil
 	}
+	return nil
 }
 
-func (s *Service) checkStatus(ctx context.Context) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// bootAttempts is the number of attempts to boot the service on a new port.
+const bootAttempts = 3
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/status", nil)
+// Boot starts the service and waits until it starts. If the process exits
+// before it starts, which happens when another process took the port before
+// the service binds it, Boot retries with a new port unless the Port is fixed.
+func (s *Service) Boot(ctx context.Context, debug bool, timeout time.Duration) error {
+	var err error
+	for i := 0; i < bootAttempts; i++ {
+		if err = s.Start(ctx, debug); err != nil {
+			return err
+		}
+		err = s.WaitForBoot(ctx, timeout)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrExited) || s.Port != 0 {
+			break
+		}
+		if logger := s.logger(debug); logger != nil {
+			logger.Warn("retry to start the service on a new port", "error", err)
+		}
+	}
+	stopCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	_ = s.Stop(stopCtx)
+	return err
+}
+
+func (s *Service) checkStatus(ctx context.Context) bool {
+	url := s.URL()
+	if url == "" {
+		return false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/status", nil)
 	if err != nil {
 		return false
 	}
@@ -1,8 +1,16 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestService_StartStop(t *testing.T) {
@@ -45,8 +53,8 @@ func TestService_StartStop(t *testing.T) {
 		}
 
 		// stop
-		if err := s.Stop(); err != nil {
-			t.Errorf("s.Stop() failed: unexpected error %v", err)
+		if err := s.Stop(context.Background()); err != nil {
+			t.Errorf("s.Stop(context.Background()) failed: unexpected error %v", err)
 		}
 		if got := s.command; got != nil {
 			t.Errorf("expected command is nil, but %p", got)
@@ -61,10 +69,10 @@ func TestService_StartStop(t *testing.T) {
 			t.Errorf("s.Start() faild: unexpected error %v", err)
 			return
 		}
-		if err := s.Stop(); err != nil {
-			t.Errorf("s.Stop() failed: unexpected error %v", err)
+		if err := s.Stop(context.Background()); err != nil {
+			t.Errorf("s.Stop(context.Background()) failed: unexpected error %v", err)
 		}
-		if err := s.Stop(); err != nil {
+		if err := s.Stop(context.Background()); err != nil {
 			if got, want := err.Error(), "already stopped"; got != want {
 				t.Errorf("want %q, got %q", want, got)
 			}
@@ -73,3 +81,150 @@ func TestService_StartStop(t *testing.T) {
 		}
 	})
 }
+
+func TestService_Output(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	logFile := filepath.Join(t.TempDir(), "driver.log")
+	s := New("localhost", []string{"sh", "-c", "echo out; printf err >&2; sleep 1"})
+	s.Stdout = &stdout
+	s.Stderr = &stderr
+	s.LogFile = logFile
+	s.Prefix = "[driver:{{.Port}}] "
+	if err := s.Start(context.Background(), false); err != nil {
+		t.Fatalf("s.Start() failed: unexpected error %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("s.Stop(context.Background()) failed: unexpected error %v", err)
+	}
+	prefix := regexp.MustCompile(`^\[driver:\d+\] `)
+	if got := stdout.String(); !prefix.MatchString(got) || !strings.HasSuffix(got, "] out\n") {
+		t.Errorf("unexpected stdout %q", got)
+	}
+	// the incomplete line is flushed on stop.
+	if got := stderr.String(); !prefix.MatchString(got) || !strings.HasSuffix(got, "] err\n") {
+		t.Errorf("unexpected stderr %q", got)
+	}
+	b, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: unexpected error %v", err)
+	}
+	if got, want := strings.Count(string(b), "\n"), 2; got != want {
+		t.Errorf("want %d lines, got %q", want, b)
+	}
+}
+
+func TestService_DoneErr(t *testing.T) {
+	t.Run("never started", func(t *testing.T) {
+		s := New("localhost", []string{"sleep", "1"})
+		if s.Done() != nil {
+			t.Errorf("expected nil channel")
+		}
+		if err := s.Err(); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	})
+	t.Run("exited", func(t *testing.T) {
+		s := New("localhost", []string{"sh", "-c", "exit 3"})
+		if err := s.Start(context.Background(), false); err != nil {
+			t.Fatalf("s.Start() failed: unexpected error %v", err)
+		}
+		select {
+		case <-s.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("service did not exit")
+		}
+		if err := s.Err(); !errors.Is(err, ErrExited) {
+			t.Errorf("want %v, got %v", ErrExited, err)
+		}
+		if got := s.URL(); got != "" {
+			t.Errorf("expected base URL is empty, but %q", got)
+		}
+		// the exited service can be started again.
+		if err := s.Start(context.Background(), false); err != nil {
+			t.Fatalf("s.Start() failed: unexpected error %v", err)
+		}
+		<-s.Done()
+	})
+	t.Run("stopped", func(t *testing.T) {
+		s := New("localhost", []string{"sleep", "10"})
+		if err := s.Start(context.Background(), false); err != nil {
+			t.Fatalf("s.Start() failed: unexpected error %v", err)
+		}
+		if err := s.Err(); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := s.Stop(context.Background()); err != nil {
+			t.Fatalf("s.Stop(context.Background()) failed: unexpected error %v", err)
+		}
+		<-s.Done()
+		if err := s.Err(); !errors.Is(err, ErrStopped) {
+			t.Errorf("want %v, got %v", ErrStopped, err)
+		}
+	})
+}
+
+func TestService_StopEscalation(t *testing.T) {
+	s := New("localhost", []string{"sh", "-c", `trap "" TERM; sleep 30`})
+	if err := s.Start(context.Background(), false); err != nil {
+		t.Fatalf("s.Start() failed: unexpected error %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("s.Stop() failed: unexpected error %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("stop took %v, the process ignoring SIGTERM should be killed after the deadline", d)
+	}
+	if err := s.Err(); !errors.Is(err, ErrStopped) {
+		t.Errorf("want %v, got %v", ErrStopped, err)
+	}
+}
+
+func TestService_Boot(t *testing.T) {
+	// the command exits immediately like a driver which failed to bind the port.
+	attempts := func(t *testing.T, port int) int {
+		t.Helper()
+		file := filepath.Join(t.TempDir(), "attempts")
+		s := New("http://{{.Address}}", []string{"sh", "-c", "echo {{.Port}} >> " + file + "; exit 1"})
+		s.Port = port
+		err := s.Boot(context.Background(), false, 5*time.Second)
+		if !errors.Is(err, ErrExited) {
+			t.Errorf("want %v, got %v", ErrExited, err)
+		}
+		b, _ := os.ReadFile(file)
+		return strings.Count(string(b), "\n")
+	}
+	t.Run("retry on a new port", func(t *testing.T) {
+		if got, want := attempts(t, 0), bootAttempts; got != want {
+			t.Errorf("want %d attempts, got %d", want, got)
+		}
+	})
+	t.Run("fixed port", func(t *testing.T) {
+		if got, want := attempts(t, 4444), 1; got != want {
+			t.Errorf("want %d attempts, got %d", want, got)
+		}
+	})
+}
+
+func TestService_Logger(t *testing.T) {
+	var b bytes.Buffer
+	s := New("localhost", []string{"sh", "-c", "echo ready; sleep 1"})
+	s.Logger = slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if err := s.Start(context.Background(), false); err != nil {
+		t.Fatalf("s.Start() failed: unexpected error %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := s.Stop(context.Background()); err != nil {
+		t.Fatalf("s.Stop(context.Background()) failed: unexpected error %v", err)
+	}
+	got := b.String()
+	for _, want := range []string{`msg="starting service"`, `msg="driver output" stream=stdout`, "line=ready"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %q in the records, got %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Status is the status of the web driver service reported by /status.
+type Status struct {
+	// Ready reports whether the service can create new sessions.
+	Ready bool
+	// Message is the human readable status (ex. "ChromeDriver ready for new sessions.").
+	Message string
+	// Build is the build information of the driver, if reported.
+	Build BuildInfo
+	// OS is the information of the platform of the driver, if reported.
+	OS OSInfo
+}
+
+// BuildInfo is the build information of the driver.
+type BuildInfo struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	Time     string `json:"time"`
+}
+
+// OSInfo is the information of the platform of the driver.
+type OSInfo struct {
+	Name    string `json:"name"`
+	Arch    string `json:"arch"`
+	Version string `json:"version"`
+}
+
+// statusTimeout is the time limit for the status request of the default client.
+const statusTimeout = 10 * time.Second
+
+// Status returns the status of the running service. The status is requested
+// with the client, or with a client timing out after 10 seconds if it is nil.
+func (s *Service) Status(ctx context.Context, client *http.Client) (*Status, error) {
+	url := s.URL()
+	if url == "" {
+		return nil, errors.New("service not started")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/status", nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: statusTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("request unsuccessful: %s: %s", resp.Status, b)
+	}
+	var body struct {
+		Value struct {
+			// the legacy protocol does not report ready.
+			Ready   *bool     `json:"ready"`
+			Message string    `json:"message"`
+			Build   BuildInfo `json:"build"`
+			OS      OSInfo    `json:"os"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, fmt.Errorf("unexpected response: %s", b)
+	}
+	v := body.Value
+	return &Status{
+		Ready:   v.Ready == nil || *v.Ready,
+		Message: v.Message,
+		Build:   v.Build,
+		OS:      v.OS,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// IsChromium returns true if the session was opened on a Chromium based browser,
+// which is reported by the browser name or by a capability of the goog: or the
+// ms: vendor prefix. It returns false if the web driver service reported neither.
+func (s *Session) IsChromium() bool {
+	for key := range s.capabilities {
+		if key == "chrome" || strings.HasPrefix(key, "goog:") || strings.HasPrefix(key, "ms:") {
+			return true
+		}
+	}
+	switch strings.ToLower(s.BrowserName()) {
+	case "chrome", "chromium", "chrome-headless-shell", "msedge", "microsoftedge":
+		return true
+	}
+	return false
+}
+
+type cdpRequest struct {
+	Cmd    string `json:"cmd"`
+	Params any    `json:"params"`
+}
+
+// ExecuteCDP executes the Chrome DevTools Protocol command (ex. "Network.clearBrowserCookies")
+// with the params, and unmarshals the result of the command into the result.
+// It returns ErrUnsupported if the session is not opened on a Chromium based browser.
+//
+// See: https://chromedevtools.github.io/devtools-protocol/
+func (s *Session) ExecuteCDP(ctx context.Context, method string, params, result any) error {
+	if !s.IsChromium() {
+		return fmt.Errorf("%w: Chrome DevTools Protocol requires a Chromium based browser, but the session is on %q", ErrUnsupported, s.BrowserName())
+	}
+	if params == nil {
+		params = struct{}{}
+	}
+	vendor := "goog"
+	if strings.EqualFold(s.BrowserName(), "msedge") {
+		vendor = "ms"
+	}
+	return s.Send(ctx, Post, vendor+"/cdp/execute", cdpRequest{
+		Cmd:    method,
+		Params: params,
+	}, result)
+}
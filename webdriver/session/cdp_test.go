@@ -0,0 +1,100 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSession_ExecuteCDP(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		path    string
+		wantErr error
+	}{
+		{
+			name:    "chrome (W3C)",
+			session: `{"value":{"sessionId":"s1","capabilities":{"browserName":"chrome"}}}`,
+			path:    "/session/s1/goog/cdp/execute",
+		},
+		{
+			name:    "chrome (legacy)",
+			session: `{"sessionId":"s1","status":0,"value":{"browserName":"chrome"}}`,
+			path:    "/session/s1/goog/cdp/execute",
+		},
+		{
+			name:    "edge",
+			session: `{"value":{"sessionId":"s1","capabilities":{"browserName":"msedge"}}}`,
+			path:    "/session/s1/ms/cdp/execute",
+		},
+		{
+			name:    "firefox",
+			session: `{"value":{"sessionId":"s1","capabilities":{"browserName":"firefox"}}}`,
+			wantErr: ErrUnsupported,
+		},
+		{
+			name:    "unreported browser",
+			session: `{"value":{"sessionId":"s1","capabilities":{}}}`,
+			wantErr: ErrUnsupported,
+		},
+		{
+			name:    "unreported browser with vendor capability",
+			session: `{"value":{"sessionId":"s1","capabilities":{"goog:chromeOptions":{"debuggerAddress":"localhost:9222"}}}}`,
+			path:    "/session/s1/goog/cdp/execute",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			var gotBody cdpRequest
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/session" {
+					_, _ = io.WriteString(w, tt.session)
+					return
+				}
+				gotPath = r.URL.Path
+				if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+					t.Errorf("json.Decode() failed: unexpected error %v", err)
+				}
+				_, _ = io.WriteString(w, `{"value":{"product":"Chrome/120.0"}}`)
+			}))
+			defer ts.Close()
+
+			s, err := OpenWithClient(context.Background(), ts.Client(), ts.URL, nil, false)
+			if err != nil {
+				t.Fatalf("OpenWithClient() failed: unexpected error %v", err)
+			}
+			if got, want := s.ID(), "s1"; got != want {
+				t.Errorf("want session ID %q, got %q", want, got)
+			}
+			var result struct{ Product string }
+			err = s.ExecuteCDP(context.Background(), "Browser.getVersion", nil, &result)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("want error %v, got %v", tt.wantErr, err)
+				}
+				if gotPath != "" {
+					t.Errorf("want no request, got %q", gotPath)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("s.ExecuteCDP() failed: unexpected error %v", err)
+			}
+			if gotPath != tt.path {
+				t.Errorf("want path %q, got %q", tt.path, gotPath)
+			}
+			if got, want := gotBody.Cmd, "Browser.getVersion"; got != want {
+				t.Errorf("want cmd %q, got %q", want, got)
+			}
+			if got, want := result.Product, "Chrome/120.0"; got != want {
+				t.Errorf("want product %q, got %q", want, got)
+			}
+		})
+	}
+}
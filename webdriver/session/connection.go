@@ -7,88 +7,152 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strings"
 )
 
-// Connection is a bus to the webdriver service.
+// Connection is a bus to the webdriver service. It is safe for concurrent use
+// by multiple goroutines, and the commands are sent one at a time because the
+// web driver processes the commands of a session sequentially. Deleting the
+// session does not wait for the pending commands, so that a stuck command
+// does not keep the session open.
 type Connection struct {
-	sessionURL string
-	httpClient *http.Client
-	debug      bool
+	serviceURL   string
+	sessionURL   string
+	sessionID    string
+	capabilities map[string]any
+	httpClient   *http.Client
+	dialect      Dialect
+	sender       Sender
+
+	sem chan struct{} // serializes the commands of the session
 }
 
-func newConnection(ctx context.Context, client *http.Client, serviceURL string, capabilities map[string]any, debug bool) (*Connection, error) {
-	req, err := capabilitiesToJSONRequest(capabilities)
+func newConnection(ctx context.Context, client *http.Client, serviceURL string, capabilities map[string]any, debug bool, dialect Dialect, middlewares []Middleware) (*Connection, error) {
+	req, err := capabilitiesToJSONRequest(capabilities, dialect)
 	if err != nil {
 		return nil, err
 	}
-	sessionID, err := openSession(ctx, client, serviceURL, req)
+	c := &Connection{
+		serviceURL: serviceURL,
+		httpClient: client,
+		dialect:    dialect,
+		sem:        make(chan struct{}, 1),
+	}
+	if debug {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		middlewares = append([]Middleware{StructuredLogging(logger)}, middlewares...)
+	}
+	c.sender = chain(c.doRequest, middlewares)
+	sessionID, granted, err := c.openSession(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	return &Connection{
-		sessionURL: serviceURL + "/session/" + sessionID,
-		httpClient: client,
-		debug:      debug,
-	}, nil
+	c.sessionURL = serviceURL + "/session/" + sessionID
+	c.sessionID = sessionID
+	c.capabilities = granted
+	return c, nil
 }
 
 type desiredCapabilities struct {
 	DesiredCapabilities map[string]any `json:"desiredCapabilities"`
 }
 
-func capabilitiesToJSONRequest(capabilities map[string]any) (io.Reader, error) {
+func capabilitiesToJSONRequest(capabilities map[string]any, dialect Dialect) ([]byte, error) {
 	if capabilities == nil {
 		capabilities = map[string]any{}
 	}
-	capabilitiesJSON, err := json.Marshal(desiredCapabilities{
+	var body any = desiredCapabilities{
 		DesiredCapabilities: capabilities,
-	})
-	if err != nil {
-		return nil, err
 	}
-	return bytes.NewReader(capabilitiesJSON), err
+	if dialect == DialectW3C {
+		alwaysMatch, err := toW3CCapabilities(capabilities)
+		if err != nil {
+			return nil, err
+		}
+		var w3c w3cCapabilities
+		w3c.Capabilities.AlwaysMatch = alwaysMatch
+		body = w3c
+	}
+	return json.Marshal(body)
 }
 
-func openSession(ctx context.Context, client *http.Client, serviceURL string, body io.Reader) (sessionID string, err error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, serviceURL+"/session", body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Add("Content-Type", "application/json")
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
+func (c *Connection) openSession(ctx context.Context, body []byte) (sessionID string, capabilities map[string]any, err error) {
+	resp, err := c.sender(ctx, &Request{
+		Method: http.MethodPost,
+		URL:    c.serviceURL + "/session",
+		Header: http.Header{},
+		Body:   body,
+	})
+	if resp == nil {
+		return "", nil, err
 	}
-	defer resp.Body.Close()
+	// the error of the unsuccessful status is returned if the body has no session ID.
+	respErr := err
 
 	var sessionResponse struct {
 		SessionID string
 		// fallback for GeckoDriver
 		Value struct {
-			SessionID string
+			SessionID    string
+			Capabilities map[string]any
 		}
 	}
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
+	b := resp.Body
 	if err := json.Unmarshal(b, &sessionResponse); err != nil {
-		return "", err
+		if respErr != nil {
+			return "", nil, respErr
+		}
+		return "", nil, err
 	}
 
 	if sessionResponse.SessionID != "" {
-		return sessionResponse.SessionID, nil
+		// the legacy protocol returns the capabilities as the value.
+		var legacy struct{ Value map[string]any }
+		_ = json.Unmarshal(b, &legacy)
+		return sessionResponse.SessionID, legacy.Value, nil
 	}
 
 	// fallback for GeckoDriver
 	if sessionResponse.Value.SessionID != "" {
-		return sessionResponse.Value.SessionID, nil
+		return sessionResponse.Value.SessionID, sessionResponse.Value.Capabilities, nil
+	}
+	if respErr != nil {
+		return "", nil, respErr
 	}
-	return "", errors.New("failed to retrieve a session ID")
+	return "", nil, errors.New("failed to retrieve a session ID")
+}
+
+// ID returns the session ID.
+func (c *Connection) ID() string {
+	return c.sessionID
+}
+
+// Dialect returns the protocol dialect of the session.
+func (c *Connection) Dialect() Dialect {
+	return c.dialect
+}
+
+// Capabilities returns the capabilities granted by the web driver service
+// when the session was opened.
+func (c *Connection) Capabilities() map[string]any {
+	return c.capabilities
+}
+
+// WebSocketURL returns the URL of the WebDriver BiDi connection granted by
+// the webSocketUrl capability, or an empty string if it was not granted.
+func (c *Connection) WebSocketURL() string {
+	url, _ := c.capabilities["webSocketUrl"].(string)
+	return url
+}
+
+// BrowserName returns the browser name granted by the web driver service,
+// or an empty string if it was not reported.
+func (c *Connection) BrowserName() string {
+	name, _ := c.capabilities["browserName"].(string)
+	return name
 }
 
 // Send sends the message to the browser.
@@ -98,14 +162,26 @@ func (c *Connection) Send(ctx context.Context, method string, pathname string, b
 		return err
 	}
 	path := strings.TrimSuffix(c.sessionURL+"/"+pathname, "/")
-	if c.debug {
-		log.Printf("%s %s", path, string(req))
+	if method != http.MethodDelete || pathname != "" {
+		select {
+		case c.sem <- struct{}{}:
+			defer func() { <-c.sem }()
+		case <-ctx.Done():
+			return fmt.Errorf("request failed: %w", ctx.Err())
+		}
 	}
-	resp, err := c.doRequest(ctx, method, path, req)
+	resp, err := c.sender(ctx, &Request{
+		SessionID: c.sessionID,
+		Method:    method,
+		URL:       path,
+		Path:      pathname,
+		Header:    http.Header{},
+		Body:      req,
+	})
 	if err != nil {
 		return err
 	}
-	if err := responseToValue(resp, result); err != nil {
+	if err := responseToValue(resp.Body, result); err != nil {
 		return err
 	}
 	return nil
@@ -133,13 +209,17 @@ func responseToValue(src []byte, dst any) error {
 	return nil
 }
 
-func (c *Connection) doRequest(ctx context.Context, method, url string, body []byte) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+// doRequest is the innermost Sender, which sends the request with the HTTP client.
+func (c *Connection) doRequest(ctx context.Context, r *Request) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, bytes.NewReader(r.Body))
 	if err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
-	if body != nil {
-		req.Header.Add("Content-Type", "application/json")
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	if r.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -151,11 +231,11 @@ func (c *Connection) doRequest(ctx context.Context, method, url string, body []b
 	if err != nil {
 		return nil, err
 	}
-
+	ret := &Response{Status: resp.StatusCode, Body: b}
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return nil, toResponseError(b)
+		return ret, toResponseError(b)
 	}
-	return b, nil
+	return ret, nil
 }
 
 func toResponseError(body []byte) error {
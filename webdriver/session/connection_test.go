@@ -0,0 +1,56 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConnection_Send(t *testing.T) {
+	stuck := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/session":
+			_, _ = w.Write([]byte(`{"value":{"sessionId":"s1","capabilities":{}}}`))
+		case r.URL.Path == "/session/s1/url":
+			close(stuck)
+			<-release
+			_, _ = w.Write([]byte(`{"value":null}`))
+		case r.URL.Path == "/session/s1" && r.Method == http.MethodDelete:
+			_, _ = w.Write([]byte(`{"value":null}`))
+		default:
+			_, _ = w.Write([]byte(`{"value":"Example"}`))
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	ctx := context.Background()
+	s, err := OpenWithDialect(ctx, ts.Client(), ts.URL, nil, false, DialectW3C)
+	if err != nil {
+		t.Fatalf("OpenWithDialect() failed: unexpected error %v", err)
+	}
+	go func() {
+		_ = s.Send(ctx, Post, "url", map[string]string{"url": "http://example.com"}, nil)
+	}()
+	<-stuck
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if _, err := s.GetTitle(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+	t.Run("delete does not wait", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		if err := s.Delete(ctx); err != nil {
+			t.Errorf("Delete() failed: unexpected error %v", err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Dialect is the protocol dialect spoken with the web driver service.
+type Dialect int
+
+const (
+	// DialectLegacy is the JSON Wire Protocol, which most drivers still accept
+	// alongside the W3C WebDriver protocol.
+	DialectLegacy Dialect = iota
+
+	// DialectW3C is the strict W3C WebDriver protocol spoken by drivers that
+	// reject the legacy endpoints and capabilities (ex. WebKitWebDriver).
+	DialectW3C
+)
+
+// String returns the name of the dialect.
+func (d Dialect) String() string {
+	switch d {
+	case DialectLegacy:
+		return "legacy"
+	case DialectW3C:
+		return "w3c"
+	default:
+		return fmt.Sprintf("Dialect(%d)", int(d))
+	}
+}
+
+// w3cElementKey is the key of the web element reference of the W3C protocol.
+const w3cElementKey = "element-6066-11e4-a52e-4f735466cecf"
+
+// w3cCapabilityNames are the capabilities defined by the W3C protocol.
+// Other capabilities have to be extension capabilities containing ":".
+var w3cCapabilityNames = map[string]bool{
+	"browserName":               true,
+	"browserVersion":            true,
+	"platformName":              true,
+	"acceptInsecureCerts":       true,
+	"pageLoadStrategy":          true,
+	"proxy":                     true,
+	"setWindowRect":             true,
+	"timeouts":                  true,
+	"strictFileInteractability": true,
+	"unhandledPromptBehavior":   true,
+	"webSocketUrl":              true,
+}
+
+type w3cCapabilities struct {
+	Capabilities struct {
+		AlwaysMatch map[string]any `json:"alwaysMatch"`
+	} `json:"capabilities"`
+}
+
+// toW3CCapabilities converts the legacy desired capabilities to the W3C
+// capabilities. The legacy capabilities which have a W3C counterpart are
+// renamed and the others are dropped, because strict drivers reject unknown
+// capabilities.
+func toW3CCapabilities(capabilities map[string]any) (map[string]any, error) {
+	ret := map[string]any{}
+	for k, v := range capabilities {
+		switch {
+		case k == "acceptSslCerts":
+			if _, ok := capabilities["acceptInsecureCerts"]; !ok {
+				ret["acceptInsecureCerts"] = v
+			}
+		case k == "version":
+			if s, _ := v.(string); s != "" {
+				ret["browserVersion"] = s
+			}
+		case k == "platform":
+			if s, _ := v.(string); s != "" && !strings.EqualFold(s, "ANY") {
+				ret["platformName"] = strings.ToLower(s)
+			}
+		case k == "proxy":
+			p, err := toW3CProxy(v)
+			if err != nil {
+				return nil, err
+			}
+			ret[k] = p
+		case w3cCapabilityNames[k] || strings.Contains(k, ":"):
+			ret[k] = v
+		}
+	}
+	return ret, nil
+}
+
+// toW3CProxy converts the legacy proxy configuration: the W3C protocol
+// requires the lower-cased proxy type and the list of the no proxy hosts.
+func toW3CProxy(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy capability: %w", err)
+	}
+	var p map[string]any
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("invalid proxy capability: %w", err)
+	}
+	if s, ok := p["proxyType"].(string); ok {
+		p["proxyType"] = strings.ToLower(s)
+	}
+	if s, ok := p["noProxy"].(string); ok {
+		var hosts []string
+		for _, h := range strings.Split(s, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				hosts = append(hosts, h)
+			}
+		}
+		p["noProxy"] = hosts
+	}
+	return p, nil
+}
+
+// legacyOnly returns ErrUnsupported if the command exists only in the
+// legacy protocol and the session speaks the W3C protocol.
+func (c *Connection) legacyOnly(command string) error {
+	if c.dialect == DialectW3C {
+		return fmt.Errorf("%w: %s in the %s dialect", ErrUnsupported, command, c.dialect)
+	}
+	return nil
+}
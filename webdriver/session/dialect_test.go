@@ -0,0 +1,149 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func Test_toW3CCapabilities(t *testing.T) {
+	testdata := []struct {
+		name string
+		in   map[string]any
+		want map[string]any
+	}{
+		{
+			name: "legacy capabilities",
+			in: map[string]any{
+				"browserName":       "MiniBrowser",
+				"acceptSslCerts":    true,
+				"version":           "",
+				"platform":          "ANY",
+				"javascriptEnabled": true,
+			},
+			want: map[string]any{
+				"browserName":         "MiniBrowser",
+				"acceptInsecureCerts": true,
+			},
+		},
+		{
+			name: "extension capabilities",
+			in: map[string]any{
+				"acceptSslCerts":           true,
+				"acceptInsecureCerts":      false,
+				"platform":                 "LINUX",
+				"webkitgtk:browserOptions": map[string]any{"args": []string{"--automation"}},
+			},
+			want: map[string]any{
+				"acceptInsecureCerts":      false,
+				"platformName":             "linux",
+				"webkitgtk:browserOptions": map[string]any{"args": []string{"--automation"}},
+			},
+		},
+		{
+			name: "proxy",
+			in: map[string]any{
+				"proxy": map[string]any{"proxyType": "MANUAL", "httpProxy": "127.0.0.1:8080", "noProxy": "localhost, 127.0.0.1"},
+			},
+			want: map[string]any{
+				"proxy": map[string]any{"proxyType": "manual", "httpProxy": "127.0.0.1:8080", "noProxy": []string{"localhost", "127.0.0.1"}},
+			},
+		},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toW3CCapabilities(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSession_W3CDialect(t *testing.T) {
+	var (
+		alwaysMatch map[string]any
+		requests    []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/session" {
+			var req w3cCapabilities
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			alwaysMatch = req.Capabilities.AlwaysMatch
+			_, _ = io.WriteString(w, `{"value":{"sessionId":"s1","capabilities":{"browserName":"MiniBrowser"}}}`)
+			return
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/session/s1/window":
+			_, _ = io.WriteString(w, `{"value":"w1"}`)
+		case "/session/s1/element/e1/rect":
+			_, _ = io.WriteString(w, `{"value":{"x":1.4,"y":2.6,"width":30,"height":40}}`)
+		default:
+			_, _ = io.WriteString(w, `{"value":null}`)
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	s, err := OpenWithDialect(ctx, ts.Client(), ts.URL, map[string]any{"browserName": "MiniBrowser", "acceptSslCerts": true}, false, DialectW3C)
+	if err != nil {
+		t.Fatalf("OpenWithDialect() failed: unexpected error %v", err)
+	}
+	if got, want := s.Dialect(), DialectW3C; got != want {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if want := map[string]any{"browserName": "MiniBrowser", "acceptInsecureCerts": true}; !reflect.DeepEqual(alwaysMatch, want) {
+		t.Errorf("want %+v, got %+v", want, alwaysMatch)
+	}
+
+	w, err := s.GetWindow(ctx)
+	if err != nil {
+		t.Fatalf("GetWindow() failed: unexpected error %v", err)
+	}
+	if w.ID != "w1" {
+		t.Errorf("want %q, got %q", "w1", w.ID)
+	}
+	if _, err := s.GetActiveElement(ctx); err != nil {
+		t.Fatalf("GetActiveElement() failed: unexpected error %v", err)
+	}
+	if err := s.AcceptAlert(ctx); err != nil {
+		t.Fatalf("AcceptAlert() failed: unexpected error %v", err)
+	}
+	if err := s.Execute(ctx, "return 1;", nil, nil); err != nil {
+		t.Fatalf("Execute() failed: unexpected error %v", err)
+	}
+	e := &Element{ID: "e1", Session: s}
+	x, y, err := e.GetLocation(ctx)
+	if err != nil {
+		t.Fatalf("GetLocation() failed: unexpected error %v", err)
+	}
+	if x != 1 || y != 3 {
+		t.Errorf("want (1, 3), got (%d, %d)", x, y)
+	}
+	want := []string{
+		"GET /session/s1/window",
+		"GET /session/s1/element/active",
+		"POST /session/s1/alert/accept",
+		"POST /session/s1/execute/sync",
+		"GET /session/s1/element/e1/rect",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("want %q, got %q", want, requests)
+	}
+
+	if err := s.Keys(ctx, "abc"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("want %v, got %v", ErrUnsupported, err)
+	}
+	if len(requests) != len(want) {
+		t.Errorf("legacy only command was sent: %q", requests[len(want):])
+	}
+}
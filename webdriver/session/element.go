@@ -94,9 +94,14 @@ func (e *Element) Value(ctx context.Context, text string) error {
 	vec := strings.Split(text, "")
 	req := struct {
 		Value []string `json:"value"`
+		// Text is required by the W3C protocol.
+		Text string `json:"text,omitempty"`
 	}{
 		Value: vec,
 	}
+	if e.Session.dialect == DialectW3C {
+		req.Text = text
+	}
 	return e.Send(ctx, Post, "value", req, nil)
 }
 
@@ -129,6 +134,9 @@ func (e *Element) IsEnabled(ctx context.Context) (bool, error) {
 
 // Submit submits the element.
 func (e *Element) Submit(ctx context.Context) error {
+	if err := e.Session.legacyOnly("submit"); err != nil {
+		return err
+	}
 	return e.Send(ctx, Post, "submit", nil, nil)
 }
 
@@ -137,6 +145,9 @@ func (e *Element) IsEqualTo(ctx context.Context, other *Element) (bool, error) {
 	if other == nil {
 		return false, errors.New("nil element is invalid")
 	}
+	if err := e.Session.legacyOnly("equals"); err != nil {
+		return false, err
+	}
 	var equal bool
 	if err := e.Send(ctx, Get, path.Join("equals", other.ID), nil, &equal); err != nil {
 		return false, err
@@ -146,6 +157,10 @@ func (e *Element) IsEqualTo(ctx context.Context, other *Element) (bool, error) {
 
 // GetLocation gets a location of the element.
 func (e *Element) GetLocation(ctx context.Context) (x, y int, err error) {
+	if e.Session.dialect == DialectW3C {
+		x, y, _, _, err := e.getRect(ctx)
+		return x, y, err
+	}
 	var location struct {
 		X float64 `json:"x"`
 		Y float64 `json:"y"`
@@ -158,6 +173,10 @@ func (e *Element) GetLocation(ctx context.Context) (x, y int, err error) {
 
 // GetSize gets a size of the element.
 func (e *Element) GetSize(ctx context.Context) (width, height int, err error) {
+	if e.Session.dialect == DialectW3C {
+		_, _, width, height, err := e.getRect(ctx)
+		return width, height, err
+	}
 	var size struct {
 		Width  float64 `json:"width"`
 		Height float64 `json:"height"`
@@ -168,6 +187,20 @@ func (e *Element) GetSize(ctx context.Context) (width, height int, err error) {
 	return round(size.Width), round(size.Height), nil
 }
 
+// getRect gets the location and the size of the element by the W3C protocol.
+func (e *Element) getRect(ctx context.Context) (x, y, width, height int, err error) {
+	var rect struct {
+		X      float64 `json:"x"`
+		Y      float64 `json:"y"`
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+	}
+	if err := e.Send(ctx, Get, "rect", nil, &rect); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return round(rect.X), round(rect.Y), round(rect.Width), round(rect.Height), nil
+}
+
 func round(number float64) int {
 	return int(number + 0.5)
 }
@@ -0,0 +1,9 @@
+package session
+
+import (
+	"errors"
+)
+
+// ErrUnsupported is returned when a command is not supported by the browser
+// or the web driver of the session.
+var ErrUnsupported = errors.New("unsupported")
@@ -0,0 +1,80 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// IsGecko returns true if the session was opened on a Gecko based browser,
+// which is reported by the browser name or by a capability of the moz: vendor
+// prefix. It returns false if the web driver service reported neither.
+func (s *Session) IsGecko() bool {
+	for key := range s.capabilities {
+		if strings.HasPrefix(key, "moz:") {
+			return true
+		}
+	}
+	switch strings.ToLower(s.BrowserName()) {
+	case "firefox":
+		return true
+	}
+	return false
+}
+
+func (s *Session) sendGecko(ctx context.Context, method, pathname string, body, result any) error {
+	if !s.IsGecko() {
+		return fmt.Errorf("%w: %s requires geckodriver, but the session is on %q", ErrUnsupported, pathname, s.BrowserName())
+	}
+	return s.Send(ctx, method, pathname, body, result)
+}
+
+type addonInstallRequest struct {
+	Addon     string `json:"addon"`
+	Temporary bool   `json:"temporary"`
+}
+
+// InstallAddon installs the add-on given as a base64 encoded XPI file and
+// returns the ID of the add-on. A temporary add-on is removed when the
+// browser is closed, and may be unsigned.
+func (s *Session) InstallAddon(ctx context.Context, addon string, temporary bool) (string, error) {
+	var id string
+	if err := s.sendGecko(ctx, Post, "moz/addon/install", addonInstallRequest{
+		Addon:     addon,
+		Temporary: temporary,
+	}, &id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+type idRequest struct {
+	ID string `json:"id"`
+}
+
+// UninstallAddon uninstalls the add-on.
+func (s *Session) UninstallAddon(ctx context.Context, id string) error {
+	return s.sendGecko(ctx, Post, "moz/addon/uninstall", idRequest{
+		ID: id,
+	}, nil)
+}
+
+type contextRequest struct {
+	Context string `json:"context"`
+}
+
+// GetContext gets the context ("chrome" or "content") of the commands.
+func (s *Session) GetContext(ctx context.Context) (string, error) {
+	var c string
+	if err := s.sendGecko(ctx, Get, "moz/context", nil, &c); err != nil {
+		return "", err
+	}
+	return c, nil
+}
+
+// SetContext sets the context ("chrome" or "content") of the commands.
+func (s *Session) SetContext(ctx context.Context, c string) error {
+	return s.sendGecko(ctx, Post, "moz/context", contextRequest{
+		Context: c,
+	}, nil)
+}
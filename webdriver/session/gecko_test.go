@@ -0,0 +1,64 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSession_GetContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		wantErr error
+	}{
+		{
+			name:    "firefox",
+			session: `{"value":{"sessionId":"s1","capabilities":{"browserName":"firefox"}}}`,
+		},
+		{
+			name:    "unreported browser with vendor capability",
+			session: `{"value":{"sessionId":"s1","capabilities":{"moz:profile":"/tmp/rust_mozprofile"}}}`,
+		},
+		{
+			name:    "unreported browser",
+			session: `{"value":{"sessionId":"s1","capabilities":{}}}`,
+			wantErr: ErrUnsupported,
+		},
+		{
+			name:    "chrome",
+			session: `{"value":{"sessionId":"s1","capabilities":{"browserName":"chrome"}}}`,
+			wantErr: ErrUnsupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/session":
+					_, _ = io.WriteString(w, tt.session)
+				case "/session/s1/moz/context":
+					_, _ = io.WriteString(w, `{"value":"content"}`)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer ts.Close()
+
+			s, err := OpenWithClient(context.Background(), ts.Client(), ts.URL, nil, false)
+			if err != nil {
+				t.Fatalf("OpenWithClient() failed: unexpected error %v", err)
+			}
+			got, err := s.GetContext(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("want %v, got %v", tt.wantErr, err)
+			}
+			if want := "content"; tt.wantErr == nil && got != want {
+				t.Errorf("want %q, got %q", want, got)
+			}
+		})
+	}
+}
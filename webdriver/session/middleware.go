@@ -0,0 +1,137 @@
+package session
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// A Request is a command sent to the web driver service.
+type Request struct {
+	// SessionID is the ID of the session, or empty for opening the session.
+	SessionID string
+	// Method is the HTTP method of the command.
+	Method string
+	// URL is the URL of the command.
+	URL string
+	// Path is the path of the command relative to the session URL, such as
+	// "element/1/click". It is empty for opening and deleting the session.
+	Path string
+	// Header is the HTTP header of the request, to which the middlewares may add.
+	Header http.Header
+	// Body is the JSON request body, or nil.
+	Body []byte
+}
+
+// A Response is the response of the web driver service.
+type Response struct {
+	// Status is the HTTP status of the response.
+	Status int
+	// Body is the response body.
+	Body []byte
+}
+
+// A Sender sends the request to the web driver service. The response is
+// returned with the error if the service responded with an unsuccessful status.
+type Sender func(ctx context.Context, req *Request) (*Response, error)
+
+// A Middleware wraps the Sender of the connection, to observe or modify the
+// requests and the responses. The middlewares are called for the commands
+// of a session one at a time.
+type Middleware func(next Sender) Sender
+
+// chain wraps the sender with the middlewares. The first middleware is the outermost.
+func chain(sender Sender, middlewares []Middleware) Sender {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		sender = middlewares[i](sender)
+	}
+	return sender
+}
+
+// Logging returns the Middleware which logs the requests and the responses
+// to the logger, or to the standard logger if it is nil.
+func Logging(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(next Sender) Sender {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			logger.Printf("%s %s %s", req.Method, req.URL, req.Body)
+			resp, err := next(ctx, req)
+			switch {
+			case resp != nil:
+				logger.Printf("%s %s %d %s", req.Method, req.URL, resp.Status, resp.Body)
+			case err != nil:
+				logger.Printf("%s %s %v", req.Method, req.URL, err)
+			}
+			return resp, err
+		}
+	}
+}
+
+// Timing returns the Middleware which reports the duration of each request
+// with its response or error to the observer.
+func Timing(observe func(req *Request, resp *Response, d time.Duration, err error)) Middleware {
+	return func(next Sender) Sender {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			start := time.Now()
+			resp, err := next(ctx, req)
+			observe(req, resp, time.Since(start), err)
+			return resp, err
+		}
+	}
+}
+
+// maxLogBody is the maximum length of the bodies in the records of StructuredLogging.
+const maxLogBody = 512
+
+// StructuredLogging returns the Middleware which logs each request as a
+// structured record with the session ID, the method, the path, the status,
+// the duration and the truncated bodies. The successful requests are logged
+// at the debug level, and the failed ones at the warn level.
+func StructuredLogging(logger *slog.Logger) Middleware {
+	return func(next Sender) Sender {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			start := time.Now()
+			resp, err := next(ctx, req)
+			attrs := []slog.Attr{
+				slog.String("session", req.SessionID),
+				slog.String("method", req.Method),
+				slog.String("path", urlPath(req.URL)),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if req.Body != nil {
+				attrs = append(attrs, slog.String("request", truncate(req.Body)))
+			}
+			if resp != nil {
+				attrs = append(attrs, slog.Int("status", resp.Status), slog.String("response", truncate(resp.Body)))
+			}
+			level := slog.LevelDebug
+			if err != nil {
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			logger.LogAttrs(ctx, level, "webdriver command", attrs...)
+			return resp, err
+		}
+	}
+}
+
+func urlPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
+
+func truncate(b []byte) string {
+	if len(b) <= maxLogBody {
+		return string(b)
+	}
+	return string(b[:maxLogBody]) + "...(" + strconv.Itoa(len(b)) + " bytes)"
+}
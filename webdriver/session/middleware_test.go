@@ -0,0 +1,155 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	var failures atomic.Int32
+	failures.Store(1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("want the injected header, got %q", got)
+		}
+		switch r.URL.Path {
+		case "/session":
+			_, _ = w.Write([]byte(`{"value":{"sessionId":"s1","capabilities":{}}}`))
+		case "/session/s1/title":
+			if failures.Add(-1) >= 0 {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"value":{"error":"unknown error","message":"busy"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"value":"Example"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Sender) Sender {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				order = append(order, name)
+				return next(ctx, req)
+			}
+		}
+	}
+	header := func(next Sender) Sender {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			req.Header.Set("Authorization", "Bearer token")
+			return next(ctx, req)
+		}
+	}
+	retry := func(next Sender) Sender {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			resp, err := next(ctx, req)
+			if resp != nil && resp.Status >= http.StatusInternalServerError {
+				return next(ctx, req)
+			}
+			return resp, err
+		}
+	}
+	var timed []string
+	timing := Timing(func(req *Request, resp *Response, d time.Duration, err error) {
+		if d <= 0 {
+			t.Errorf("unexpected duration %v", d)
+		}
+		timed = append(timed, req.Method+" "+req.Path)
+	})
+	var logs bytes.Buffer
+	logging := Logging(log.New(&logs, "", 0))
+
+	ctx := context.Background()
+	s, err := OpenWithDialect(ctx, ts.Client(), ts.URL, nil, false, DialectLegacy, trace("outer"), trace("inner"), timing, logging, retry, header)
+	if err != nil {
+		t.Fatalf("OpenWithDialect() failed: unexpected error %v", err)
+	}
+	title, err := s.GetTitle(ctx)
+	if err != nil {
+		t.Fatalf("GetTitle() failed: unexpected error %v", err)
+	}
+	if title != "Example" {
+		t.Errorf("want %q, got %q", "Example", title)
+	}
+	if got, want := strings.Join(order, ","), "outer,inner,outer,inner"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got, want := strings.Join(timed, ","), "POST ,GET title"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	// the retried request is logged once.
+	if got, want := logs.String(), "GET "+ts.URL+"/session/s1/title 200 {\"value\":\"Example\"}\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("want the suffix %q, got %q", want, got)
+	}
+	if got, want := strings.Count(logs.String(), "\n"), 4; got != want {
+		t.Errorf("want %d lines, got %q", want, logs.String())
+	}
+}
+
+func TestStructuredLogging(t *testing.T) {
+	long := strings.Repeat("x", maxLogBody+10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/session":
+			_, _ = w.Write([]byte(`{"value":{"sessionId":"s1","capabilities":{}}}`))
+		case "/session/s1/source":
+			_, _ = w.Write([]byte(`{"value":"` + long + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"value":{"error":"unknown command","message":"unknown command"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	var b bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ctx := context.Background()
+	s, err := OpenWithDialect(ctx, ts.Client(), ts.URL, nil, false, DialectLegacy, StructuredLogging(logger))
+	if err != nil {
+		t.Fatalf("OpenWithDialect() failed: unexpected error %v", err)
+	}
+	if _, err := s.GetSource(ctx); err != nil {
+		t.Fatalf("GetSource() failed: unexpected error %v", err)
+	}
+	if _, err := s.GetTitle(ctx); err == nil {
+		t.Fatalf("expected error, but nil")
+	}
+
+	var records []map[string]any
+	dec := json.NewDecoder(&b)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("Decode() failed: unexpected error %v", err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 3 {
+		t.Fatalf("want 3 records, got %d", len(records))
+	}
+	source, title := records[1], records[2]
+	if source["level"] != "DEBUG" || source["session"] != "s1" || source["method"] != "GET" || source["path"] != "/session/s1/source" || source["status"] != float64(200) {
+		t.Errorf("unexpected record %v", source)
+	}
+	if _, ok := source["duration"]; !ok {
+		t.Errorf("want the duration, got %v", source)
+	}
+	if got := source["response"].(string); len(got) > maxLogBody+32 || !strings.HasSuffix(got, " bytes)") {
+		t.Errorf("want the truncated body, got %q", got)
+	}
+	if title["level"] != "WARN" || title["status"] != float64(404) || title["error"] == nil {
+		t.Errorf("unexpected record %v", title)
+	}
+}
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NetworkConditions represents the network conditions emulated by chromedriver.
+// The numbers are doubles, which chromedriver reports as 100.0 or 1.5e+06.
+type NetworkConditions struct {
+	Offline bool `json:"offline"`
+	// Latency is the additional latency in milliseconds.
+	Latency float64 `json:"latency"`
+	// DownloadThroughput is the maximal throughput in bytes/sec.
+	DownloadThroughput float64 `json:"download_throughput"`
+	// UploadThroughput is the maximal throughput in bytes/sec.
+	UploadThroughput float64 `json:"upload_throughput"`
+}
+
+type networkConditionsRequest struct {
+	NetworkConditions NetworkConditions `json:"network_conditions"`
+}
+
+const networkConditionsPath = "chromium/network_conditions"
+
+func (s *Session) sendChromium(ctx context.Context, method, pathname string, body, result any) error {
+	if !s.IsChromium() {
+		return fmt.Errorf("%w: %s requires chromedriver, but the session is on %q", ErrUnsupported, pathname, s.BrowserName())
+	}
+	if err := s.Send(ctx, method, pathname, body, result); err != nil {
+		if strings.Contains(err.Error(), "unknown command") {
+			return fmt.Errorf("%w: %v", ErrUnsupported, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetNetworkConditions gets the emulated network conditions of the browser.
+func (s *Session) GetNetworkConditions(ctx context.Context) (*NetworkConditions, error) {
+	var conditions NetworkConditions
+	if err := s.sendChromium(ctx, Get, networkConditionsPath, nil, &conditions); err != nil {
+		return nil, err
+	}
+	return &conditions, nil
+}
+
+// SetNetworkConditions sets the emulated network conditions to the browser.
+func (s *Session) SetNetworkConditions(ctx context.Context, conditions NetworkConditions) error {
+	return s.sendChromium(ctx, Post, networkConditionsPath, networkConditionsRequest{
+		NetworkConditions: conditions,
+	}, nil)
+}
+
+// DeleteNetworkConditions disables the emulation of the network conditions.
+func (s *Session) DeleteNetworkConditions(ctx context.Context) error {
+	return s.sendChromium(ctx, Delete, networkConditionsPath, nil, nil)
+}
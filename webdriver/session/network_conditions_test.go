@@ -0,0 +1,108 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSession_NetworkConditions(t *testing.T) {
+	var stored *NetworkConditions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/session":
+			var req desiredCapabilities
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			name, _ := req.DesiredCapabilities["browserName"].(string)
+			_, _ = io.WriteString(w, `{"value":{"sessionId":"s1","capabilities":{"browserName":"`+name+`"}}}`)
+		case r.URL.Path != "/session/s1/chromium/network_conditions":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"value":{"error":"unknown command","message":"unknown command"}}`)
+		case r.Method == http.MethodPost:
+			var req networkConditionsRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("json.Decode() failed: unexpected error %v", err)
+			}
+			stored = &req.NetworkConditions
+			_, _ = io.WriteString(w, `{"value":null}`)
+		case r.Method == http.MethodGet:
+			if stored == nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = io.WriteString(w, `{"value":{"error":"unknown error","message":"network conditions must be set before it can be retrieved"}}`)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"value": stored})
+		case r.Method == http.MethodDelete:
+			stored = nil
+			_, _ = io.WriteString(w, `{"value":null}`)
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	t.Run("chrome", func(t *testing.T) {
+		s, err := OpenWithClient(ctx, ts.Client(), ts.URL, map[string]any{"browserName": "chrome"}, false)
+		if err != nil {
+			t.Fatalf("OpenWithClient() failed: unexpected error %v", err)
+		}
+		want := NetworkConditions{Latency: 100, DownloadThroughput: 1000, UploadThroughput: 500}
+		if err := s.SetNetworkConditions(ctx, want); err != nil {
+			t.Fatalf("s.SetNetworkConditions() failed: unexpected error %v", err)
+		}
+		got, err := s.GetNetworkConditions(ctx)
+		if err != nil {
+			t.Fatalf("s.GetNetworkConditions() failed: unexpected error %v", err)
+		}
+		if *got != want {
+			t.Errorf("want %+v, got %+v", want, *got)
+		}
+		if err := s.DeleteNetworkConditions(ctx); err != nil {
+			t.Fatalf("s.DeleteNetworkConditions() failed: unexpected error %v", err)
+		}
+		if _, err := s.GetNetworkConditions(ctx); err == nil {
+			t.Errorf("expected error, but nil")
+		}
+	})
+	t.Run("firefox", func(t *testing.T) {
+		s, err := OpenWithClient(ctx, ts.Client(), ts.URL, map[string]any{"browserName": "firefox"}, false)
+		if err != nil {
+			t.Fatalf("OpenWithClient() failed: unexpected error %v", err)
+		}
+		if err := s.SetNetworkConditions(ctx, NetworkConditions{Offline: true}); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("want %v, got %v", ErrUnsupported, err)
+		}
+	})
+}
+
+func TestSession_GetNetworkConditions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/session":
+			_, _ = io.WriteString(w, `{"value":{"sessionId":"s1","capabilities":{"browserName":"chrome"}}}`)
+		case "/session/s1/chromium/network_conditions":
+			// captured from chromedriver 120.
+			_, _ = io.WriteString(w, `{"value":{"download_throughput":1.5e+06,"latency":100.0,"offline":false,"upload_throughput":96000.5}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	ctx := context.Background()
+
+	s, err := OpenWithClient(ctx, ts.Client(), ts.URL, nil, false)
+	if err != nil {
+		t.Fatalf("OpenWithClient() failed: unexpected error %v", err)
+	}
+	got, err := s.GetNetworkConditions(ctx)
+	if err != nil {
+		t.Fatalf("s.GetNetworkConditions() failed: unexpected error %v", err)
+	}
+	want := NetworkConditions{Latency: 100, DownloadThroughput: 1500000, UploadThroughput: 96000.5}
+	if *got != want {
+		t.Errorf("want %+v, got %+v", want, *got)
+	}
+}
@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SeleniumHubPath is the path of the session endpoints of the Selenium server
+// kept for the clients of the Selenium 3 hub.
+const SeleniumHubPath = "/wd/hub"
+
+type graphQLRequest struct {
+	Query string `json:"query"`
+}
+
+// nodeURICapability is the capability in which the Selenium Grid reports the
+// node of the session.
+const nodeURICapability = "se:nodeUri"
+
+// NodeURI returns the URI of the Selenium Grid node on which the session was
+// granted. It is read from the se:nodeUri capability granted by the Selenium
+// server, or else retrieved by the GraphQL endpoint at the root of the server.
+//
+// See: https://www.selenium.dev/documentation/grid/advanced_features/graphql_support/
+func (s *Session) NodeURI(ctx context.Context) (string, error) {
+	if uri, ok := s.capabilities[nodeURICapability].(string); ok && uri != "" {
+		return uri, nil
+	}
+	root := strings.TrimSuffix(s.serviceURL, SeleniumHubPath)
+	body, err := bodyToJSON(graphQLRequest{
+		Query: fmt.Sprintf(`{ session (id: %q) { nodeUri } }`, s.sessionID),
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.sender(ctx, &Request{
+		SessionID: s.sessionID,
+		Method:    http.MethodPost,
+		URL:       root + "/graphql",
+		Header:    http.Header{},
+		Body:      body,
+	})
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Data struct {
+			Session struct {
+				NodeURI string `json:"nodeUri"`
+			} `json:"session"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.Body, &result); err != nil {
+		return "", fmt.Errorf("unexpected response: %s", resp.Body)
+	}
+	if len(result.Errors) > 0 {
+		return "", fmt.Errorf("request unsuccessful: %s", result.Errors[0].Message)
+	}
+	if result.Data.Session.NodeURI == "" {
+		return "", fmt.Errorf("%w: node URI of the session %s", ErrUnsupported, s.sessionID)
+	}
+	return result.Data.Session.NodeURI, nil
+}
@@ -0,0 +1,70 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSession_NodeURI(t *testing.T) {
+	tests := []struct {
+		name         string
+		capabilities string
+		graphQL      bool
+		want         string
+	}{
+		{
+			name:         "capability",
+			capabilities: `{"browserName":"firefox","se:name":"login test","se:nodeUri":"http://10.0.0.3:5555"}`,
+			want:         "http://10.0.0.3:5555",
+		},
+		{
+			name:         "graphql",
+			capabilities: `{"browserName":"firefox","se:name":"login test"}`,
+			graphQL:      true,
+			want:         "http://10.0.0.2:5555",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case SeleniumHubPath + "/session":
+					_, _ = io.WriteString(w, `{"value":{"sessionId":"s1","capabilities":`+tt.capabilities+`}}`)
+				case "/graphql":
+					if !tt.graphQL {
+						t.Errorf("unexpected GraphQL request")
+					}
+					var req graphQLRequest
+					if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+						t.Errorf("json.Decode() failed: unexpected error %v", err)
+					}
+					if want := `{ session (id: "s1") { nodeUri } }`; req.Query != want {
+						t.Errorf("want %q, got %q", want, req.Query)
+					}
+					_, _ = io.WriteString(w, `{"data":{"session":{"nodeUri":"http://10.0.0.2:5555"}}}`)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = io.WriteString(w, `{"value":{"error":"unknown command","message":"unknown command"}}`)
+				}
+			}))
+			defer ts.Close()
+			ctx := context.Background()
+
+			s, err := OpenWithClient(ctx, ts.Client(), ts.URL+SeleniumHubPath, map[string]any{"browserName": "firefox", "se:name": "login test"}, false)
+			if err != nil {
+				t.Fatalf("OpenWithClient() failed: unexpected error %v", err)
+			}
+			got, err := s.NodeURI(ctx)
+			if err != nil {
+				t.Fatalf("NodeURI() failed: unexpected error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
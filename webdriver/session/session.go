@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ikawaha/navigator/event"
@@ -19,6 +20,10 @@ const (
 	// DefaultSessionClientTimeout is the default time limit for requests
 	// to the web driver service of the session client.
 	DefaultSessionClientTimeout = 30 * time.Second
+
+	// DefaultStopTimeout is the waiting time limit for the web driver service
+	// to delete the sessions and exit before it is killed.
+	DefaultStopTimeout = 10 * time.Second
 )
 
 // Method is the (HTTP) method to access to the web driver service.
@@ -38,11 +43,21 @@ const (
 // Session represents a session to the web driver service.
 type Session struct {
 	*Connection
+
+	mu       sync.Mutex
+	onDelete []func()
 }
 
 // OpenWithClient returns a session to the web driver service.
 func OpenWithClient(ctx context.Context, client *http.Client, url string, capabilities map[string]any, debug bool) (*Session, error) {
-	c, err := newConnection(ctx, client, url, capabilities, debug)
+	return OpenWithDialect(ctx, client, url, capabilities, debug, DialectLegacy)
+}
+
+// OpenWithDialect returns a session to the web driver service which speaks
+// the protocol dialect. The commands of the session, including the request
+// opening it, are sent through the middlewares.
+func OpenWithDialect(ctx context.Context, client *http.Client, url string, capabilities map[string]any, debug bool, dialect Dialect, middlewares ...Middleware) (*Session, error) {
+	c, err := newConnection(ctx, client, url, capabilities, debug, dialect, middlewares)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +66,24 @@ func OpenWithClient(ctx context.Context, client *http.Client, url string, capabi
 
 // Delete sends to delete message to terminate the session.
 func (s *Session) Delete(ctx context.Context) error {
-	return s.Send(ctx, Delete, "", nil, nil)
+	if err := s.Send(ctx, Delete, "", nil, nil); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	hooks := s.onDelete
+	s.onDelete = nil
+	s.mu.Unlock()
+	for _, f := range hooks {
+		f()
+	}
+	return nil
+}
+
+// OnDelete registers the function called once after the session is deleted.
+func (s *Session) OnDelete(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onDelete = append(s.onDelete, f)
 }
 
 // Selector represents a selector for elements.
@@ -87,8 +119,12 @@ func (s *Session) GetElements(ctx context.Context, selector Selector) ([]*Elemen
 
 // GetActiveElement returns the active element of the session.
 func (s *Session) GetActiveElement(ctx context.Context) (*Element, error) {
+	method := Post
+	if s.dialect == DialectW3C {
+		method = Get
+	}
 	var result elementResult
-	if err := s.Send(ctx, Post, "element/active", nil, &result); err != nil {
+	if err := s.Send(ctx, method, "element/active", nil, &result); err != nil {
 		return nil, err
 	}
 	return &Element{ID: result.ID(), Session: s}, nil
@@ -96,8 +132,12 @@ func (s *Session) GetActiveElement(ctx context.Context) (*Element, error) {
 
 // GetWindow returns the window handler of the session.
 func (s *Session) GetWindow(ctx context.Context) (*Window, error) {
+	pathname := "window_handle"
+	if s.dialect == DialectW3C {
+		pathname = "window"
+	}
 	var windowID string
-	if err := s.Send(ctx, Get, "window_handle", nil, &windowID); err != nil {
+	if err := s.Send(ctx, Get, pathname, nil, &windowID); err != nil {
 		return nil, err
 	}
 	return &Window{ID: windowID, Session: s}, nil
@@ -105,8 +145,12 @@ func (s *Session) GetWindow(ctx context.Context) (*Window, error) {
 
 // GetWindows returns window handlers of the session.
 func (s *Session) GetWindows(ctx context.Context) ([]*Window, error) {
+	pathname := "window_handles"
+	if s.dialect == DialectW3C {
+		pathname = "window/handles"
+	}
 	var windowsID []string
-	if err := s.Send(ctx, Get, "window_handles", nil, &windowsID); err != nil {
+	if err := s.Send(ctx, Get, pathname, nil, &windowsID); err != nil {
 		return nil, err
 	}
 
@@ -121,18 +165,26 @@ type nameRequest struct {
 	Name string `json:"name"`
 }
 
+type handleRequest struct {
+	Handle string `json:"handle"`
+}
+
 // SetWindow sets the window to the browser.
 func (s *Session) SetWindow(ctx context.Context, window *Window) error {
 	if window == nil {
 		return errors.New("nil window is invalid")
 	}
-	return s.Send(ctx, Post, "window", nameRequest{
-		Name: window.ID,
-	}, nil)
+	return s.SetWindowByName(ctx, window.ID)
 }
 
 // SetWindowByName sets the window to the browser by name.
+// The W3C protocol switches the window only by the window handle.
 func (s *Session) SetWindowByName(ctx context.Context, name string) error {
+	if s.dialect == DialectW3C {
+		return s.Send(ctx, Post, "window", handleRequest{
+			Handle: name,
+		}, nil)
+	}
 	return s.Send(ctx, Post, "window", nameRequest{
 		Name: name,
 	}, nil)
@@ -228,6 +280,9 @@ func (s *Session) GetSource(ctx context.Context) (string, error) {
 
 // MoveTo moves the element to the offset position.
 func (s *Session) MoveTo(ctx context.Context, region *Element, offset Offset) error {
+	if err := s.legacyOnly("moveto"); err != nil {
+		return err
+	}
 	req := map[string]any{}
 	if region != nil {
 		req["element"] = region.ID
@@ -246,7 +301,9 @@ func (s *Session) MoveTo(ctx context.Context, region *Element, offset Offset) er
 // Frame sets the frame to the browser.
 func (s *Session) Frame(ctx context.Context, frame *Element) error {
 	var elementID any
-	if frame != nil {
+	if frame != nil && s.dialect == DialectW3C {
+		elementID = map[string]string{w3cElementKey: frame.ID}
+	} else if frame != nil {
 		elementID = struct {
 			Element string `json:"ELEMENT"`
 		}{
@@ -276,7 +333,11 @@ func (s *Session) Execute(ctx context.Context, body string, arguments []any, res
 	if arguments == nil {
 		arguments = []any{}
 	}
-	return s.Send(ctx, Post, "execute", scriptRequest{
+	pathname := "execute"
+	if s.dialect == DialectW3C {
+		pathname = "execute/sync"
+	}
+	return s.Send(ctx, Post, pathname, scriptRequest{
 		Script: body,
 		Args:   arguments,
 	}, result)
@@ -300,7 +361,7 @@ func (s *Session) Refresh(ctx context.Context) error {
 // GetAlertText gets the alert text of the browser.
 func (s *Session) GetAlertText(ctx context.Context) (string, error) {
 	var text string
-	if err := s.Send(ctx, Get, "alert_text", nil, &text); err != nil {
+	if err := s.Send(ctx, Get, s.alertPath("alert_text"), nil, &text); err != nil {
 		return "", err
 	}
 	return text, nil
@@ -312,19 +373,34 @@ type textRequest struct {
 
 // SetAlertText sets the text to the browser.
 func (s *Session) SetAlertText(ctx context.Context, text string) error {
-	return s.Send(ctx, Post, "alert_text", textRequest{
+	return s.Send(ctx, Post, s.alertPath("alert_text"), textRequest{
 		Text: text,
 	}, nil)
 }
 
 // AcceptAlert accepts the alert of the browser.
 func (s *Session) AcceptAlert(ctx context.Context) error {
-	return s.Send(ctx, Post, "accept_alert", nil, nil)
+	return s.Send(ctx, Post, s.alertPath("accept_alert"), nil, nil)
 }
 
 // DismissAlert dismisses the alert of the browser.
 func (s *Session) DismissAlert(ctx context.Context) error {
-	return s.Send(ctx, Post, "dismiss_alert", nil, nil)
+	return s.Send(ctx, Post, s.alertPath("dismiss_alert"), nil, nil)
+}
+
+// alertPath returns the path of the legacy alert command in the dialect of the session.
+func (s *Session) alertPath(legacy string) string {
+	if s.dialect != DialectW3C {
+		return legacy
+	}
+	switch legacy {
+	case "alert_text":
+		return "alert/text"
+	case "accept_alert":
+		return "alert/accept"
+	default:
+		return "alert/dismiss"
+	}
 }
 
 type typeRequest struct {
@@ -357,21 +433,33 @@ type buttonRequest struct {
 
 // DoubleClick sends the double click event to the browser.
 func (s *Session) DoubleClick(ctx context.Context) error {
+	if err := s.legacyOnly("doubleclick"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Post, "doubleclick", nil, nil)
 }
 
 // Click sends the click event to the browser.
 func (s *Session) Click(ctx context.Context, button event.Button) error {
+	if err := s.legacyOnly("click"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Post, "click", buttonRequest{Button: button}, nil)
 }
 
 // ButtonDown sends the button down event to the browser.
 func (s *Session) ButtonDown(ctx context.Context, button event.Button) error {
+	if err := s.legacyOnly("buttondown"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Post, "buttondown", buttonRequest{Button: button}, nil)
 }
 
 // ButtonUp sends the button up event to the browser.
 func (s *Session) ButtonUp(ctx context.Context, button event.Button) error {
+	if err := s.legacyOnly("buttonup"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Post, "buttonup", buttonRequest{Button: button}, nil)
 }
 
@@ -382,6 +470,9 @@ type xyRequest struct {
 
 // TouchDown sends the touch-down event to the browser.
 func (s *Session) TouchDown(ctx context.Context, x, y int) error {
+	if err := s.legacyOnly("touch/down"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Post, "touch/down", xyRequest{
 		X: x,
 		Y: y,
@@ -390,6 +481,9 @@ func (s *Session) TouchDown(ctx context.Context, x, y int) error {
 
 // TouchUp sends the touch-up event to the browser.
 func (s *Session) TouchUp(ctx context.Context, x, y int) error {
+	if err := s.legacyOnly("touch/up"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Post, "touch/up", xyRequest{
 		X: x,
 		Y: y,
@@ -398,6 +492,9 @@ func (s *Session) TouchUp(ctx context.Context, x, y int) error {
 
 // TouchMove sends the touch-move event to the browser.
 func (s *Session) TouchMove(ctx context.Context, x, y int) error {
+	if err := s.legacyOnly("touch/move"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Post, "touch/move", xyRequest{
 		X: x,
 		Y: y,
@@ -410,6 +507,9 @@ type elementRequest struct {
 
 // TouchClick sends touch-click event to the browser.
 func (s *Session) TouchClick(ctx context.Context, element *Element) error {
+	if err := s.legacyOnly("touch/click"); err != nil {
+		return err
+	}
 	if element == nil {
 		return errors.New("nil element is invalid")
 	}
@@ -420,6 +520,9 @@ func (s *Session) TouchClick(ctx context.Context, element *Element) error {
 
 // TouchDoubleClick sends the touch-double-click event to the browser.
 func (s *Session) TouchDoubleClick(ctx context.Context, element *Element) error {
+	if err := s.legacyOnly("touch/doubleclick"); err != nil {
+		return err
+	}
 	if element == nil {
 		return errors.New("nil element is invalid")
 	}
@@ -430,6 +533,9 @@ func (s *Session) TouchDoubleClick(ctx context.Context, element *Element) error
 
 // TouchLongClick sends the touch-long-click event to the browser.
 func (s *Session) TouchLongClick(ctx context.Context, element *Element) error {
+	if err := s.legacyOnly("touch/longclick"); err != nil {
+		return err
+	}
 	if element == nil {
 		return errors.New("nil element is invalid")
 	}
@@ -452,6 +558,9 @@ type touchFlickRequest struct {
 
 // TouchFlick sends the touch-flick event to the browser.
 func (s *Session) TouchFlick(ctx context.Context, element *Element, offset Offset, speed Speed) error {
+	if err := s.legacyOnly("touch/flick"); err != nil {
+		return err
+	}
 	if speed == nil {
 		return errors.New("nil speed is invalid")
 	}
@@ -482,6 +591,9 @@ type touchScrollRequest struct {
 
 // TouchScroll sends the touch-scroll event to the browser.
 func (s *Session) TouchScroll(ctx context.Context, element *Element, offset Offset) error {
+	if err := s.legacyOnly("touch/scroll"); err != nil {
+		return err
+	}
 	if element == nil {
 		element = &Element{}
 	}
@@ -502,6 +614,9 @@ type valueSliceRequest struct {
 
 // Keys sends key events of the text to the browser.
 func (s *Session) Keys(ctx context.Context, text string) error {
+	if err := s.legacyOnly("keys"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Post, "keys", valueSliceRequest{
 		Value: strings.Split(text, ""),
 	}, nil)
@@ -509,11 +624,17 @@ func (s *Session) Keys(ctx context.Context, text string) error {
 
 // DeleteLocalStorage deletes the local storage of the browser.
 func (s *Session) DeleteLocalStorage(ctx context.Context) error {
+	if err := s.legacyOnly("local_storage"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Delete, "local_storage", nil, nil)
 }
 
 // DeleteSessionStorage deletes the session storage of the browser.
 func (s *Session) DeleteSessionStorage(ctx context.Context) error {
+	if err := s.legacyOnly("session_storage"); err != nil {
+		return err
+	}
 	return s.Send(ctx, Delete, "session_storage", nil, nil)
 }
 
@@ -524,6 +645,9 @@ type msRequest struct {
 
 // SetImplicitWait sets the implicit wait to the browser.
 func (s *Session) SetImplicitWait(ctx context.Context, timeout int) error {
+	if s.dialect == DialectW3C {
+		return s.Send(ctx, Post, "timeouts", map[string]int{"implicit": timeout}, nil)
+	}
 	return s.Send(ctx, Post, "timeouts/implicit_wait", msRequest{
 		MS: timeout,
 	}, nil)
@@ -531,6 +655,9 @@ func (s *Session) SetImplicitWait(ctx context.Context, timeout int) error {
 
 // SetPageLoad sets the timeout to the page load of the browser.
 func (s *Session) SetPageLoad(ctx context.Context, timeout int) error {
+	if s.dialect == DialectW3C {
+		return s.Send(ctx, Post, "timeouts", map[string]int{"pageLoad": timeout}, nil)
+	}
 	return s.Send(ctx, Post, "timeouts", msRequest{
 		MS:   timeout,
 		Type: "page load",
@@ -539,6 +666,9 @@ func (s *Session) SetPageLoad(ctx context.Context, timeout int) error {
 
 // SetScriptTimeout sets the timeout to the asynchronous script execution.
 func (s *Session) SetScriptTimeout(ctx context.Context, timeout int) error {
+	if s.dialect == DialectW3C {
+		return s.Send(ctx, Post, "timeouts", map[string]int{"script": timeout}, nil)
+	}
 	return s.Send(ctx, Post, "timeouts/async_script", msRequest{
 		MS: timeout,
 	}, nil)
@@ -22,7 +22,14 @@ type widthHeightRequest struct {
 }
 
 // SetSize sets the size of the window of the browser.
+// The W3C protocol resizes only the current window.
 func (w *Window) SetSize(ctx context.Context, width, height int) error {
+	if w.Session.dialect == DialectW3C {
+		return w.Session.Send(ctx, Post, "window/rect", widthHeightRequest{
+			Width:  width,
+			Height: height,
+		}, nil)
+	}
 	return w.Send(ctx, Post, "size", widthHeightRequest{
 		Width:  width,
 		Height: height,
@@ -0,0 +1,64 @@
+// Command fakedriver is a minimal web driver service for the tests of the
+// webdriver package. POST /session/{id}/crash makes the process exit.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+func main() {
+	port := flag.String("port", "", "port to listen on")
+	flag.Parse()
+
+	var (
+		mu       sync.Mutex
+		next     int
+		sessions = map[string]bool{}
+	)
+	writeValue := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"value": v})
+	}
+	http.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeValue(w, map[string]any{
+			"ready":   true,
+			"message": "fakedriver ready",
+			"build":   map[string]any{"version": "1.0.0"},
+			"os":      map[string]any{"name": runtime.GOOS, "arch": runtime.GOARCH},
+		})
+	})
+	http.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		next++
+		id := fmt.Sprintf("s%d", next)
+		sessions[id] = true
+		mu.Unlock()
+		writeValue(w, map[string]any{"sessionId": id, "capabilities": map[string]any{"browserName": "fake"}})
+	})
+	http.HandleFunc("/session/", func(w http.ResponseWriter, r *http.Request) {
+		id, command, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/session/"), "/")
+		mu.Lock()
+		defer mu.Unlock()
+		if !sessions[id] {
+			w.WriteHeader(http.StatusNotFound)
+			writeValue(w, map[string]any{"error": "invalid session id", "message": "invalid session id"})
+			return
+		}
+		switch {
+		case command == "crash":
+			os.Exit(2)
+		case command == "" && r.Method == http.MethodDelete:
+			delete(sessions, id)
+		}
+		writeValue(w, nil)
+	})
+	log.Fatal(http.ListenAndServe("127.0.0.1:"+*port, nil))
+}
@@ -2,21 +2,70 @@ package webdriver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/ikawaha/navigator/webdriver/service"
 	"github.com/ikawaha/navigator/webdriver/session"
 )
 
-// WebDriver represents a web driver service/client.
+// WebDriver represents a web driver service/client. The exported fields have
+// to be set before Start; the methods are safe for concurrent use.
 type WebDriver struct {
 	Timeout    time.Duration
 	Debug      bool
 	HTTPClient *http.Client
+	// Dialect is the protocol dialect of the sessions opened by the web driver.
+	Dialect session.Dialect
+	// Stdout and Stderr receive the output of the driver process.
+	Stdout io.Writer
+	Stderr io.Writer
+	// LogFile is the path of the file the output of the driver process is appended to.
+	LogFile string
+	// LogPrefix is the template of the prefix of each output line (ex. "[chromedriver:{{.Port}}] ").
+	LogPrefix string
+	// LogPath and Verbose are given to the command template as {{.LogPath}} and {{.Verbose}}.
+	LogPath string
+	Verbose bool
+	// Host is the host the service binds to. The default is "localhost".
+	Host string
+	// Port is the fixed port of the service. If zero, a free port between
+	// MinPort and MaxPort, or any free port if they are zero, is used.
+	Port    int
+	MinPort int
+	MaxPort int
+	// AllowedIPs are given to the command template as comma separated {{.AllowedIPs}}.
+	AllowedIPs []string
+	// Fields are the custom fields given to the URL and command templates as {{.Fields.name}}.
+	Fields map[string]string
+	// Middlewares wrap the commands of the sessions opened by the web driver.
+	Middlewares []session.Middleware
+	// Logger receives the structured records of the service and the commands
+	// of the sessions. If nil, they are logged to stderr in debug mode.
+	Logger *slog.Logger
+
 	service    *service.Service
+	mu         sync.Mutex // guards sessions
 	sessions   []*session.Session
+	runMu      sync.Mutex // serializes start, stop and restart
+	stopped    bool
+	supervisor func(Crash)
+}
+
+// Crash is reported by the supervisor when the driver process exits unexpectedly.
+type Crash struct {
+	// Err is the error of the exited process.
+	Err error
+	// Lost are the sessions opened on the exited process, which cannot be used anymore.
+	Lost []*session.Session
+	// RestartErr is the error of restarting the driver, or nil if the driver was restarted.
+	RestartErr error
 }
 
 // New creates the web driver service/client.
@@ -48,33 +97,154 @@ func (w *WebDriver) OpenWithContext(ctx context.Context, desiredCapabilities map
 	if url == "" {
 		return nil, fmt.Errorf("service not started")
 	}
-	s, err := session.OpenWithClient(ctx, w.HTTPClient, url, desiredCapabilities, w.Debug)
+	debug, middlewares := w.Debug, w.Middlewares
+	if w.Logger != nil {
+		debug = false
+		middlewares = append([]session.Middleware{session.StructuredLogging(w.Logger)}, middlewares...)
+	}
+	s, err := session.OpenWithDialect(ctx, w.HTTPClient, url, desiredCapabilities, debug, w.Dialect, middlewares...)
 	if err != nil {
 		return nil, err
 	}
+	w.mu.Lock()
 	w.sessions = append(w.sessions, s)
+	w.mu.Unlock()
+	s.OnDelete(func() {
+		w.remove(s)
+	})
 	return s, nil
 }
 
+// remove removes the deleted session from the sessions.
+func (w *WebDriver) remove(s *session.Session) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.sessions = slices.DeleteFunc(w.sessions, func(v *session.Session) bool {
+		return v == s
+	})
+}
+
+// Sessions returns the sessions opened by the web driver and not deleted yet.
+func (w *WebDriver) Sessions() []*session.Session {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.sessions)
+}
+
+// CloseAll deletes all the sessions opened by the web driver. The sessions
+// are forgotten even if the deletion fails.
+func (w *WebDriver) CloseAll(ctx context.Context) error {
+	w.mu.Lock()
+	sessions := w.sessions
+	w.sessions = nil
+	w.mu.Unlock()
+	var errs []error
+	for _, s := range sessions {
+		if err := s.Delete(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete session %s: %w", s.ID(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Status returns the status of the web driver service.
+func (w *WebDriver) Status(ctx context.Context) (*service.Status, error) {
+	status, err := w.service.Status(ctx, w.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status: %w", err)
+	}
+	return status, nil
+}
+
+// Supervise enables the supervisor, which restarts the driver when its process
+// exits unexpectedly and reports the crash with the lost sessions to the handler.
+// It has to be called before Start. The handler is called on the goroutine of
+// the supervisor, which stops when the driver fails to restart.
+func (w *WebDriver) Supervise(handler func(Crash)) {
+	w.runMu.Lock()
+	defer w.runMu.Unlock()
+	w.supervisor = handler
+}
+
 // Start starts the web driver service.
 func (w *WebDriver) Start(ctx context.Context) error {
-	if err := w.service.Start(ctx, w.Debug); err != nil {
-		return fmt.Errorf("failed to start service: %w", err)
-	}
-	if err := w.service.WaitForBoot(ctx, w.Timeout); err != nil {
-		_ = w.service.Stop()
+	w.runMu.Lock()
+	defer w.runMu.Unlock()
+	if err := w.boot(ctx); err != nil {
 		return err
 	}
+	w.stopped = false
+	if w.supervisor != nil {
+		go w.supervise(w.service.Done())
+	}
 	return nil
 }
 
-// Stop stops the web driver service.
-func (w *WebDriver) Stop() error {
-	ctx := context.Background() // with deadline ?
-	for _, v := range w.sessions {
-		_ = v.DeleteWindow(ctx)
+func (w *WebDriver) boot(ctx context.Context) error {
+	w.service.Stdout = w.Stdout
+	w.service.Stderr = w.Stderr
+	w.service.LogFile = w.LogFile
+	w.service.Prefix = w.LogPrefix
+	w.service.LogPath = w.LogPath
+	w.service.Verbose = w.Verbose
+	w.service.Host = w.Host
+	w.service.Port = w.Port
+	w.service.MinPort = w.MinPort
+	w.service.MaxPort = w.MaxPort
+	w.service.AllowedIPs = w.AllowedIPs
+	w.service.Fields = w.Fields
+	w.service.Logger = w.Logger
+	if err := w.service.Boot(ctx, w.Debug, w.Timeout); err != nil {
+		return fmt.Errorf("failed to start service: %w", err)
+	}
+	return nil
+}
+
+// supervise restarts the driver each time its process exits unexpectedly.
+func (w *WebDriver) supervise(done <-chan struct{}) {
+	for {
+		<-done
+		err := w.service.Err()
+		if errors.Is(err, service.ErrStopped) {
+			return
+		}
+		w.runMu.Lock()
+		if w.stopped {
+			w.runMu.Unlock()
+			return
+		}
+		w.mu.Lock()
+		lost := w.sessions
+		w.sessions = nil
+		w.mu.Unlock()
+		restartErr := w.boot(context.Background())
+		done = w.service.Done()
+		w.runMu.Unlock()
+
+		w.supervisor(Crash{Err: err, Lost: lost, RestartErr: restartErr})
+		if restartErr != nil {
+			return
+		}
 	}
-	if err := w.service.Stop(); err != nil {
+}
+
+// Stop stops the web driver service. It waits for the service to exit for
+// DefaultStopTimeout at most before killing it.
+func (w *WebDriver) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), session.DefaultStopTimeout)
+	defer cancel()
+	return w.StopWithContext(ctx)
+}
+
+// StopWithContext stops the web driver service. It deletes the open sessions,
+// terminates the service and waits for it to exit until the context is done,
+// then kills it.
+func (w *WebDriver) StopWithContext(ctx context.Context) error {
+	w.runMu.Lock()
+	defer w.runMu.Unlock()
+	w.stopped = true
+	_ = w.CloseAll(ctx)
+	if err := w.service.Stop(ctx); err != nil {
 		return fmt.Errorf("failed to stop service: %w", err)
 	}
 	return nil
@@ -0,0 +1,127 @@
+package webdriver
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ikawaha/navigator/webdriver/service"
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+// buildFakeDriver builds testdata/fakedriver and returns the path to the binary.
+func buildFakeDriver(t *testing.T) string {
+	t.Helper()
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not available")
+	}
+	name := "fakedriver"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if out, err := exec.Command(gobin, "build", "-o", path, "./testdata/fakedriver").CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, out)
+	}
+	return path
+}
+
+func TestWebDriver_Supervise(t *testing.T) {
+	driver := New("http://{{.Address}}", []string{buildFakeDriver(t), "--port={{.Port}}"})
+	crashes := make(chan Crash, 1)
+	driver.Supervise(func(c Crash) {
+		crashes <- c
+	})
+	ctx := context.Background()
+	if err := driver.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: unexpected error %v", err)
+	}
+	s, err := driver.OpenWithContext(ctx, nil)
+	if err != nil {
+		t.Fatalf("OpenWithContext() failed: unexpected error %v", err)
+	}
+	// the driver exits before responding.
+	_ = s.Send(ctx, session.Post, "crash", nil, nil)
+
+	var crash Crash
+	select {
+	case crash = <-crashes:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("crash was not reported")
+	}
+	if !errors.Is(crash.Err, service.ErrExited) {
+		t.Errorf("want %v, got %v", service.ErrExited, crash.Err)
+	}
+	if crash.RestartErr != nil {
+		t.Fatalf("unexpected restart error: %v", crash.RestartErr)
+	}
+	if len(crash.Lost) != 1 || crash.Lost[0] != s {
+		t.Errorf("want the lost session %q, got %v", s.ID(), crash.Lost)
+	}
+	// the restarted driver accepts new sessions.
+	if _, err := driver.OpenWithContext(ctx, nil); err != nil {
+		t.Errorf("OpenWithContext() failed: unexpected error %v", err)
+	}
+	if err := driver.Stop(); err != nil {
+		t.Errorf("Stop() failed: unexpected error %v", err)
+	}
+	select {
+	case c := <-crashes:
+		t.Errorf("unexpected crash after stop: %+v", c)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWebDriver_Sessions(t *testing.T) {
+	driver := New("http://{{.Address}}", []string{buildFakeDriver(t), "--port={{.Port}}"})
+	ctx := context.Background()
+	if err := driver.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: unexpected error %v", err)
+	}
+	defer driver.Stop()
+
+	status, err := driver.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() failed: unexpected error %v", err)
+	}
+	if !status.Ready || status.Message != "fakedriver ready" || status.Build.Version != "1.0.0" || status.OS.Name != runtime.GOOS {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	var opened []*session.Session
+	for i := 0; i < 3; i++ {
+		s, err := driver.OpenWithContext(ctx, nil)
+		if err != nil {
+			t.Fatalf("OpenWithContext() failed: unexpected error %v", err)
+		}
+		opened = append(opened, s)
+	}
+	if got := driver.Sessions(); len(got) != 3 {
+		t.Fatalf("want 3 sessions, got %d", len(got))
+	}
+	if err := opened[1].Delete(ctx); err != nil {
+		t.Fatalf("Delete() failed: unexpected error %v", err)
+	}
+	got := driver.Sessions()
+	if len(got) != 2 || got[0] != opened[0] || got[1] != opened[2] {
+		t.Errorf("want the sessions %q and %q, got %v", opened[0].ID(), opened[2].ID(), got)
+	}
+	if name := got[0].BrowserName(); name != "fake" {
+		t.Errorf("want %q, got %q", "fake", name)
+	}
+	if err := driver.CloseAll(ctx); err != nil {
+		t.Errorf("CloseAll() failed: unexpected error %v", err)
+	}
+	if got := driver.Sessions(); len(got) != 0 {
+		t.Errorf("want no sessions, got %v", got)
+	}
+	// the deleted session is unknown to the driver.
+	if err := opened[0].Delete(ctx); err == nil {
+		t.Errorf("expected error, but nil")
+	}
+}
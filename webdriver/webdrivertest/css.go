@@ -0,0 +1,117 @@
+package webdrivertest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compound is a compound CSS selector such as `input.large[type="text"]`.
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// cssSelector is a chain of compound selectors joined with descendant combinators.
+type cssSelector []compound
+
+// parseCSS parses the subset of CSS selectors supported by the server: the
+// type, id, class and attribute selectors, descendant combinators and selector lists.
+func parseCSS(s string) ([]cssSelector, error) {
+	var ret []cssSelector
+	for _, group := range strings.Split(s, ",") {
+		var sel cssSelector
+		for _, field := range strings.Fields(group) {
+			c, err := parseCompound(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector %q: %w", s, err)
+			}
+			sel = append(sel, c)
+		}
+		if len(sel) == 0 {
+			return nil, fmt.Errorf("invalid selector %q", s)
+		}
+		ret = append(ret, sel)
+	}
+	return ret, nil
+}
+
+func parseCompound(s string) (compound, error) {
+	var c compound
+	name := func() string {
+		i := strings.IndexAny(s, "#.[")
+		if i < 0 {
+			i = len(s)
+		}
+		v := s[:i]
+		s = s[i:]
+		return v
+	}
+	c.tag = name()
+	for s != "" {
+		switch s[0] {
+		case '#':
+			s = s[1:]
+			c.id = name()
+		case '.':
+			s = s[1:]
+			c.classes = append(c.classes, name())
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return c, fmt.Errorf("unclosed attribute selector")
+			}
+			var a attrSelector
+			a.name, a.value, a.hasValue = strings.Cut(s[1:end], "=")
+			a.value = strings.Trim(a.value, `"'`)
+			c.attrs = append(c.attrs, a)
+			s = s[end+1:]
+		}
+	}
+	if c.tag == "*" {
+		c.tag = ""
+	}
+	return c, nil
+}
+
+func (c compound) matches(e *Element) bool {
+	if c.tag != "" && c.tag != e.Tag {
+		return false
+	}
+	if c.id != "" && c.id != e.Attributes["id"] {
+		return false
+	}
+	for _, class := range c.classes {
+		if !hasClass(e, class) {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		v, ok := e.attribute(a.name)
+		if !ok || a.hasValue && v != a.value {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the element with the ancestors matches the selector.
+func (s cssSelector) matches(e *Element, ancestors []*Element) bool {
+	if !s[len(s)-1].matches(e) {
+		return false
+	}
+	i := len(s) - 2
+	for j := len(ancestors) - 1; j >= 0 && i >= 0; j-- {
+		if s[i].matches(ancestors[j]) {
+			i--
+		}
+	}
+	return i < 0
+}
@@ -0,0 +1,18 @@
+// Package webdrivertest provides a fake web driver server for unit tests.
+//
+// The server implements the session, element and window endpoints used by
+// the webdriver/session package on an in-memory element model, so that code
+// using navigator can be tested without a browser:
+//
+//	srv := webdrivertest.NewServer()
+//	defer srv.Close()
+//	srv.Handle("http://example.com/", &webdrivertest.Document{
+//		Title: "Example",
+//		Elements: []*webdrivertest.Element{
+//			{Tag: "button", Attributes: map[string]string{"id": "ok"}, Text: "OK"},
+//		},
+//	})
+//
+// Failures can be injected with Inject, and the received commands can be
+// asserted with AssertReceived and AssertNotReceived.
+package webdrivertest
@@ -0,0 +1,184 @@
+package webdrivertest
+
+import (
+	"strings"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+// A Document is a page served at a URL.
+type Document struct {
+	// Title is the title of the page.
+	Title string
+	// Source is the page source. If empty, the source is rendered from the elements.
+	Source string
+	// Elements are the top level elements of the page.
+	Elements []*Element
+}
+
+// An Element is an element of a document. The fields may be modified by the
+// tests through Server.Update while the server is running.
+type Element struct {
+	// Tag is the tag name of the element.
+	Tag string
+	// Text is the visible text of the element.
+	Text string
+	// Attributes are the attributes of the element, such as "id", "name" and "class".
+	Attributes map[string]string
+	// CSS are the computed CSS properties of the element.
+	CSS map[string]string
+	// Value is the value of the input element, which is appended to by the sent keys.
+	Value string
+	// Selected is the state of the checkbox, radio button or option element.
+	Selected bool
+	// Hidden and Disabled make the element not displayed and not enabled.
+	Hidden   bool
+	Disabled bool
+	// X, Y, Width and Height are the rectangle of the element.
+	X, Y, Width, Height int
+	// Selectors are additional selectors matching the element, such as the
+	// XPath queries which are not evaluated by the server.
+	Selectors []session.Selector
+	// Alert is the text of the alert opened when the element is clicked.
+	Alert string
+	// OnClick is called with the lock of the server held when the element is
+	// clicked. It may modify the model but must not call the methods of the server.
+	OnClick func()
+	// Children are the child elements.
+	Children []*Element
+}
+
+// walk calls f for each element of the tree in document order until f returns false.
+func walk(elements []*Element, f func(*Element) bool) bool {
+	for _, e := range elements {
+		if !f(e) || !walk(e.Children, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkAncestors calls f for each element of the tree in document order with its ancestors.
+func walkAncestors(elements, ancestors []*Element, f func(e *Element, ancestors []*Element)) {
+	for _, e := range elements {
+		f(e, ancestors)
+		walkAncestors(e.Children, append(ancestors, e), f)
+	}
+}
+
+func (d *Document) contains(target *Element) bool {
+	found := false
+	walk(d.Elements, func(e *Element) bool {
+		found = e == target
+		return !found
+	})
+	return found
+}
+
+func (d *Document) source() string {
+	if d.Source != "" {
+		return d.Source
+	}
+	var b strings.Builder
+	b.WriteString("<html><head><title>" + d.Title + "</title></head><body>")
+	for _, e := range d.Elements {
+		e.render(&b)
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func (e *Element) render(b *strings.Builder) {
+	b.WriteString("<" + e.Tag)
+	for k, v := range e.Attributes {
+		b.WriteString(" " + k + `="` + v + `"`)
+	}
+	b.WriteString(">" + e.Text)
+	for _, c := range e.Children {
+		c.render(b)
+	}
+	b.WriteString("</" + e.Tag + ">")
+}
+
+// attribute returns the attribute, or the value of the input element.
+func (e *Element) attribute(name string) (string, bool) {
+	if v, ok := e.Attributes[name]; ok {
+		return v, true
+	}
+	switch name {
+	case "value":
+		return e.Value, true
+	case "checked", "selected":
+		if e.Selected {
+			return "true", true
+		}
+	case "disabled":
+		if e.Disabled {
+			return "true", true
+		}
+	}
+	return "", false
+}
+
+// find returns the descendants of the roots matching the selector.
+func find(roots []*Element, selector session.Selector) ([]*Element, error) {
+	var match func(*Element) bool
+	switch selector.Using {
+	case "css selector":
+		selectors, err := parseCSS(selector.Value)
+		if err != nil {
+			return nil, err
+		}
+		var found []*Element
+		walkAncestors(roots, nil, func(e *Element, ancestors []*Element) {
+			for _, s := range selectors {
+				if s.matches(e, ancestors) {
+					found = append(found, e)
+					return
+				}
+			}
+			if hasSelector(e, selector) {
+				found = append(found, e)
+			}
+		})
+		return found, nil
+	case "id", "name":
+		match = func(e *Element) bool { return e.Attributes[selector.Using] == selector.Value }
+	case "class name":
+		match = func(e *Element) bool { return hasClass(e, selector.Value) }
+	case "tag name":
+		match = func(e *Element) bool { return e.Tag == selector.Value }
+	case "link text":
+		match = func(e *Element) bool { return e.Tag == "a" && strings.TrimSpace(e.Text) == selector.Value }
+	case "partial link text":
+		match = func(e *Element) bool { return e.Tag == "a" && strings.Contains(e.Text, selector.Value) }
+	default:
+		match = func(*Element) bool { return false }
+	}
+	var found []*Element
+	walk(roots, func(e *Element) bool {
+		if match(e) || hasSelector(e, selector) {
+			found = append(found, e)
+		}
+		return true
+	})
+	return found, nil
+}
+
+func hasSelector(e *Element, selector session.Selector) bool {
+	for _, s := range e.Selectors {
+		if s == selector {
+			return true
+		}
+	}
+	return false
+}
+
+func hasClass(e *Element, class string) bool {
+	for _, c := range strings.Fields(e.Attributes["class"]) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package webdrivertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"testing"
+	"time"
+)
+
+// The error codes of the WebDriver protocol.
+const (
+	InvalidArgument        = "invalid argument"
+	InvalidSelector        = "invalid selector"
+	InvalidSessionID       = "invalid session id"
+	JavaScriptError        = "javascript error"
+	NoSuchAlert            = "no such alert"
+	NoSuchElement          = "no such element"
+	StaleElementReference  = "stale element reference"
+	ElementNotInteractable = "element not interactable"
+	Timeout                = "timeout"
+	UnexpectedAlertOpen    = "unexpected alert open"
+	UnknownCommand         = "unknown command"
+	UnknownError           = "unknown error"
+)
+
+var errorStatus = map[string]int{
+	InvalidArgument:        http.StatusBadRequest,
+	InvalidSelector:        http.StatusBadRequest,
+	InvalidSessionID:       http.StatusNotFound,
+	JavaScriptError:        http.StatusInternalServerError,
+	NoSuchAlert:            http.StatusNotFound,
+	NoSuchElement:          http.StatusNotFound,
+	StaleElementReference:  http.StatusNotFound,
+	ElementNotInteractable: http.StatusBadRequest,
+	Timeout:                http.StatusInternalServerError,
+	UnexpectedAlertOpen:    http.StatusInternalServerError,
+	UnknownCommand:         http.StatusNotFound,
+	UnknownError:           http.StatusInternalServerError,
+}
+
+// A Fault is a failure injected into the commands matching the method and the path.
+type Fault struct {
+	// Method is the HTTP method of the commands. If empty, any method matches.
+	Method string
+	// Path is the pattern of the command path relative to the session, such
+	// as "url" or "element/*/click", in the syntax of path.Match. If empty,
+	// any command matches.
+	Path string
+	// Delay delays the response, which makes the client time out if it is
+	// longer than the timeout of the client.
+	Delay time.Duration
+	// Error is the error code of the response, such as StaleElementReference.
+	// If empty, the command succeeds after the delay.
+	Error string
+	// Message is the error message.
+	Message string
+	// Status is the HTTP status of the error. If zero, the status of the error code is used.
+	Status int
+	// Times is the number of the commands the fault is injected into. If zero,
+	// it is injected into all the matching commands.
+	Times int
+}
+
+func (f *Fault) matches(c Command) bool {
+	if f.Method != "" && f.Method != c.Method {
+		return false
+	}
+	return f.Path == "" || matchPath(f.Path, c.Path)
+}
+
+func (f *Fault) status() int {
+	if f.Status != 0 {
+		return f.Status
+	}
+	if status, ok := errorStatus[f.Error]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// A Command is a request received by the server.
+type Command struct {
+	// SessionID is the ID of the session of the command.
+	SessionID string
+	// Method is the HTTP method of the command.
+	Method string
+	// Path is the path relative to the session URL, such as "element/1/click".
+	// It is empty for opening and deleting the session.
+	Path string
+	// Body is the JSON request body.
+	Body []byte
+}
+
+func matchPath(pattern, name string) bool {
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+// Inject injects the fault into the following commands.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes the injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// fault returns the fault injected into the command, or nil.
+func (s *Server) fault(c Command) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.faults {
+		if !f.matches(c) {
+			continue
+		}
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		ret := *f
+		return &ret
+	}
+	return nil
+}
+
+// Commands returns the commands received by the server.
+func (s *Server) Commands() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Command(nil), s.commands...)
+}
+
+// Overlapped returns the commands received while another command of the
+// same session was in flight, which a web driver processing the commands of
+// a session sequentially does not expect.
+func (s *Server) Overlapped() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Command(nil), s.overlapped...)
+}
+
+// A CDPCommand is a Chrome DevTools Protocol command received by the server.
+type CDPCommand struct {
+	// SessionID is the ID of the session of the command.
+	SessionID string
+	// Cmd is the method of the command (ex. "Emulation.setTimezoneOverride").
+	Cmd string `json:"cmd"`
+	// Params are the parameters of the command.
+	Params map[string]any `json:"params"`
+}
+
+// CDPCommands returns the Chrome DevTools Protocol commands received by the server.
+func (s *Server) CDPCommands() []CDPCommand {
+	var ret []CDPCommand
+	for _, c := range s.Commands() {
+		if c.Method != http.MethodPost || (c.Path != "goog/cdp/execute" && c.Path != "ms/cdp/execute") {
+			continue
+		}
+		v := CDPCommand{SessionID: c.SessionID}
+		if err := json.Unmarshal(c.Body, &v); err != nil {
+			continue
+		}
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// Received returns the commands matching the method and the path pattern.
+// The pattern is in the syntax of path.Match.
+func (s *Server) Received(method, pattern string) []Command {
+	var ret []Command
+	for _, c := range s.Commands() {
+		if c.Method == method && matchPath(pattern, c.Path) {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// AssertReceived fails the test if no command matches the method and the path pattern.
+func (s *Server) AssertReceived(t testing.TB, method, pattern string) {
+	t.Helper()
+	if len(s.Received(method, pattern)) == 0 {
+		t.Errorf("want the command %s %s, got none", method, pattern)
+	}
+}
+
+// AssertNotReceived fails the test if a command matches the method and the path pattern.
+func (s *Server) AssertNotReceived(t testing.TB, method, pattern string) {
+	t.Helper()
+	if got := s.Received(method, pattern); len(got) != 0 {
+		t.Errorf("want no command %s %s, got %d", method, pattern, len(got))
+	}
+}
@@ -0,0 +1,557 @@
+package webdrivertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+const (
+	aboutBlankURL = "about:blank"
+	windowHandle  = "window-1"
+	// w3cElementKey is the key of the element reference of the W3C protocol.
+	w3cElementKey = "element-6066-11e4-a52e-4f735466cecf"
+)
+
+// A Server is a fake web driver server. The sessions share the documents
+// handled by the server, and each session has its own location, history,
+// cookies and alert.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex // guards the model, the sessions, the faults and the commands
+	documents  map[string]*Document
+	scripts    map[string]any
+	logs       map[string][]session.Log
+	sessions   map[string]*browser
+	elements   map[string]*Element
+	ids        map[*Element]string
+	nextID     int
+	faults     []*Fault
+	commands   []Command
+	inFlight   map[string]int
+	overlapped []Command
+}
+
+// browser is the state of a session.
+type browser struct {
+	url      string
+	history  []string
+	pos      int
+	cookies  []session.Cookie
+	alert    *string
+	timeouts map[string]int
+}
+
+// NewServer starts and returns a new fake web driver server. The caller
+// should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		documents: map[string]*Document{},
+		scripts:   map[string]any{},
+		logs:      map[string][]session.Log{},
+		sessions:  map[string]*browser{},
+		elements:  map[string]*Element{},
+		ids:       map[*Element]string{},
+		inFlight:  map[string]int{},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Handle serves the document at the URL. The sessions navigated to the
+// other URLs show an empty document.
+func (s *Server) Handle(url string, doc *Document) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.documents[url] = doc
+}
+
+// HandleScript makes the script executed by the sessions return the result.
+// The other scripts return null.
+func (s *Server) HandleScript(script string, result any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[script] = result
+}
+
+// HandleLog makes the log command of the type (ex. "browser") return the
+// entries on every call. The other types return no entries.
+func (s *Server) HandleLog(logType string, entries ...session.Log) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs[logType] = entries
+}
+
+// Lose makes the session unknown to the server, as a web driver does after
+// the browser crashed. The following commands of the session fail with
+// InvalidSessionID.
+func (s *Server) Lose(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// Update calls f with the lock of the server held, so that f can modify the
+// documents and the elements while the sessions are running.
+func (s *Server) Update(f func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f()
+}
+
+// SessionURL returns the URL the session is navigated to, or an empty string if
+// the session does not exist.
+func (s *Server) SessionURL(id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.sessions[id]; ok {
+		return b.url
+	}
+	return ""
+}
+
+// Sessions returns the IDs of the open sessions.
+func (s *Server) Sessions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// responseError is an error response of the WebDriver protocol.
+type responseError struct {
+	code    string
+	message string
+}
+
+func (e *responseError) Error() string {
+	return e.code + ": " + e.message
+}
+
+func errorf(code, format string, a ...any) *responseError {
+	return &responseError{code: code, message: fmt.Sprintf(format, a...)}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	p := strings.Trim(r.URL.Path, "/")
+	if p == "status" {
+		writeValue(w, http.StatusOK, map[string]any{"ready": true, "message": "webdrivertest ready"})
+		return
+	}
+	rest, ok := strings.CutPrefix(p, "session")
+	if !ok {
+		writeError(w, errorf(UnknownCommand, "unknown command: %s %s", r.Method, r.URL.Path))
+		return
+	}
+	id, pathname, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	c := Command{SessionID: id, Method: r.Method, Path: pathname, Body: body}
+	if id == "" && r.Method == http.MethodPost {
+		c.SessionID = s.newSessionID()
+	}
+	s.mu.Lock()
+	s.commands = append(s.commands, c)
+	if id != "" {
+		if s.inFlight[id]++; s.inFlight[id] > 1 {
+			s.overlapped = append(s.overlapped, c)
+		}
+	}
+	s.mu.Unlock()
+	if id != "" {
+		defer func() {
+			s.mu.Lock()
+			s.inFlight[id]--
+			s.mu.Unlock()
+		}()
+	}
+
+	if f := s.fault(c); f != nil {
+		select {
+		case <-time.After(f.Delay):
+		case <-r.Context().Done():
+			return
+		}
+		if f.Error != "" {
+			w.WriteHeader(f.status())
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"value": map[string]string{"error": f.Error, "message": f.Message},
+			})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	v, err := s.execute(c)
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeValue(w, http.StatusOK, v)
+}
+
+func writeValue(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"value": v})
+}
+
+func writeError(w http.ResponseWriter, err *responseError) {
+	status, ok := errorStatus[err.code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	writeValue(w, status, map[string]string{"error": err.code, "message": err.message})
+}
+
+func (s *Server) newSessionID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return "session-" + strconv.Itoa(s.nextID)
+}
+
+// execute executes the command with the lock held.
+func (s *Server) execute(c Command) (any, *responseError) {
+	if c.Path == "" && c.Method == http.MethodPost {
+		return s.newSession(c)
+	}
+	b, ok := s.sessions[c.SessionID]
+	if !ok {
+		return nil, errorf(InvalidSessionID, "session %s not found", c.SessionID)
+	}
+	if c.Path == "" && c.Method == http.MethodDelete {
+		delete(s.sessions, c.SessionID)
+		return nil, nil
+	}
+	if rest, ok := strings.CutPrefix(c.Path, "element/"); ok {
+		if id, pathname, ok := strings.Cut(rest, "/"); ok {
+			e, err := s.element(b, id)
+			if err != nil {
+				return nil, err
+			}
+			return s.executeElement(b, e, c.Method, pathname, c.Body)
+		}
+	}
+	return s.executeSession(b, c.Method, c.Path, c.Body)
+}
+
+func (s *Server) newSession(c Command) (any, *responseError) {
+	var req struct {
+		DesiredCapabilities map[string]any `json:"desiredCapabilities"`
+		Capabilities        struct {
+			AlwaysMatch map[string]any `json:"alwaysMatch"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(c.Body, &req); err != nil {
+		return nil, errorf(InvalidArgument, "invalid capabilities: %v", err)
+	}
+	capabilities := map[string]any{}
+	for k, v := range req.DesiredCapabilities {
+		capabilities[k] = v
+	}
+	for k, v := range req.Capabilities.AlwaysMatch {
+		capabilities[k] = v
+	}
+	if _, ok := capabilities["browserName"]; !ok {
+		capabilities["browserName"] = "webdrivertest"
+	}
+	s.sessions[c.SessionID] = &browser{
+		url:      aboutBlankURL,
+		history:  []string{aboutBlankURL},
+		timeouts: map[string]int{},
+	}
+	return map[string]any{"sessionId": c.SessionID, "capabilities": capabilities}, nil
+}
+
+func (s *Server) document(b *browser) *Document {
+	if doc, ok := s.documents[b.url]; ok {
+		return doc
+	}
+	return &Document{}
+}
+
+// reference returns the element reference in both of the protocols.
+func (s *Server) reference(e *Element) map[string]string {
+	id, ok := s.ids[e]
+	if !ok {
+		id = strconv.Itoa(len(s.ids) + 1)
+		s.ids[e] = id
+		s.elements[id] = e
+	}
+	return map[string]string{"ELEMENT": id, w3cElementKey: id}
+}
+
+// element returns the element of the ID, which has to be in the document of the session.
+func (s *Server) element(b *browser, id string) (*Element, *responseError) {
+	e, ok := s.elements[id]
+	if !ok {
+		return nil, errorf(NoSuchElement, "element %s not found", id)
+	}
+	if !s.document(b).contains(e) {
+		return nil, errorf(StaleElementReference, "element %s is not attached to the page document", id)
+	}
+	return e, nil
+}
+
+func (s *Server) findElements(roots []*Element, body []byte, single bool) (any, *responseError) {
+	var selector session.Selector
+	if err := json.Unmarshal(body, &selector); err != nil {
+		return nil, errorf(InvalidArgument, "invalid selector: %v", err)
+	}
+	found, err := find(roots, selector)
+	if err != nil {
+		return nil, errorf(InvalidSelector, "%v", err)
+	}
+	if single {
+		if len(found) == 0 {
+			return nil, errorf(NoSuchElement, "no such element: %s %q", selector.Using, selector.Value)
+		}
+		return s.reference(found[0]), nil
+	}
+	refs := []map[string]string{}
+	for _, e := range found {
+		refs = append(refs, s.reference(e))
+	}
+	return refs, nil
+}
+
+func (b *browser) navigate(url string) {
+	b.history = append(b.history[:b.pos+1], url)
+	b.pos++
+	b.url = url
+	b.alert = nil
+}
+
+func (s *Server) executeSession(b *browser, method, pathname string, body []byte) (any, *responseError) {
+	if b.alert != nil && !isAlertCommand(pathname) && method == http.MethodPost && pathname != "timeouts" {
+		return nil, errorf(UnexpectedAlertOpen, "unexpected alert open: %s", *b.alert)
+	}
+	switch method + " " + pathname {
+	case "POST url":
+		var req struct{ URL string }
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, errorf(InvalidArgument, "invalid url: %v", err)
+		}
+		b.navigate(req.URL)
+		return nil, nil
+	case "GET url":
+		return b.url, nil
+	case "GET title":
+		return s.document(b).Title, nil
+	case "GET source":
+		return s.document(b).source(), nil
+	case "POST back":
+		if b.pos > 0 {
+			b.pos--
+			b.url = b.history[b.pos]
+		}
+		return nil, nil
+	case "POST forward":
+		if b.pos < len(b.history)-1 {
+			b.pos++
+			b.url = b.history[b.pos]
+		}
+		return nil, nil
+	case "POST refresh", "POST frame", "POST frame/parent", "POST window", "POST window/rect",
+		"POST window/" + windowHandle + "/size", "DELETE local_storage", "DELETE session_storage":
+		return nil, nil
+	case "POST element", "POST elements":
+		return s.findElements(s.document(b).Elements, body, pathname == "element")
+	case "GET element/active", "POST element/active":
+		doc := s.document(b)
+		if len(doc.Elements) == 0 {
+			return nil, errorf(NoSuchElement, "no active element")
+		}
+		return s.reference(doc.Elements[0]), nil
+	case "GET window", "GET window_handle":
+		return windowHandle, nil
+	case "GET window/handles", "GET window_handles":
+		return []string{windowHandle}, nil
+	case "DELETE window":
+		return []string{}, nil
+	case "GET cookie":
+		cookies := b.cookies
+		if cookies == nil {
+			cookies = []session.Cookie{}
+		}
+		return cookies, nil
+	case "POST cookie":
+		var req struct{ Cookie session.Cookie }
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, errorf(InvalidArgument, "invalid cookie: %v", err)
+		}
+		b.deleteCookie(req.Cookie.Name)
+		b.cookies = append(b.cookies, req.Cookie)
+		return nil, nil
+	case "DELETE cookie":
+		b.cookies = nil
+		return nil, nil
+	case "GET screenshot":
+		return "", nil
+	case "POST execute", "POST execute/sync", "POST execute_async", "POST execute/async":
+		var req struct{ Script string }
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, errorf(InvalidArgument, "invalid script: %v", err)
+		}
+		return s.scripts[req.Script], nil
+	case "GET alert_text", "GET alert/text":
+		if b.alert == nil {
+			return nil, errorf(NoSuchAlert, "no such alert")
+		}
+		return *b.alert, nil
+	case "POST alert_text", "POST alert/text":
+		if b.alert == nil {
+			return nil, errorf(NoSuchAlert, "no such alert")
+		}
+		return nil, nil
+	case "POST accept_alert", "POST alert/accept", "POST dismiss_alert", "POST alert/dismiss":
+		if b.alert == nil {
+			return nil, errorf(NoSuchAlert, "no such alert")
+		}
+		b.alert = nil
+		return nil, nil
+	case "POST log":
+		var req struct{ Type string }
+		_ = json.Unmarshal(body, &req)
+		entries := []map[string]any{}
+		for _, v := range s.logs[req.Type] {
+			entries = append(entries, map[string]any{"message": v.Message, "level": v.Level, "timestamp": v.Timestamp})
+		}
+		return entries, nil
+	case "POST goog/cdp/execute", "POST ms/cdp/execute":
+		return map[string]any{}, nil
+	case "GET log/types":
+		return []string{"browser"}, nil
+	case "POST timeouts", "POST timeouts/implicit_wait", "POST timeouts/async_script":
+		var req map[string]any
+		_ = json.Unmarshal(body, &req)
+		for k, v := range req {
+			if ms, ok := v.(float64); ok {
+				b.timeouts[k] = int(ms)
+			}
+		}
+		return nil, nil
+	}
+	if name, ok := strings.CutPrefix(pathname, "cookie/"); ok && method == http.MethodDelete {
+		b.deleteCookie(name)
+		return nil, nil
+	}
+	return nil, errorf(UnknownCommand, "unknown command: %s %s", method, pathname)
+}
+
+func isAlertCommand(pathname string) bool {
+	return strings.HasPrefix(pathname, "alert") || strings.HasSuffix(pathname, "_alert")
+}
+
+func (b *browser) deleteCookie(name string) {
+	var cookies []session.Cookie
+	for _, c := range b.cookies {
+		if c.Name != name {
+			cookies = append(cookies, c)
+		}
+	}
+	b.cookies = cookies
+}
+
+func (s *Server) executeElement(b *browser, e *Element, method, pathname string, body []byte) (any, *responseError) {
+	if b.alert != nil {
+		return nil, errorf(UnexpectedAlertOpen, "unexpected alert open: %s", *b.alert)
+	}
+	switch method + " " + pathname {
+	case "POST element", "POST elements":
+		return s.findElements(e.Children, body, pathname == "element")
+	case "GET text":
+		if e.Hidden {
+			return "", nil
+		}
+		return e.Text, nil
+	case "GET name":
+		return e.Tag, nil
+	case "GET selected":
+		return e.Selected, nil
+	case "GET displayed":
+		return !e.Hidden, nil
+	case "GET enabled":
+		return !e.Disabled, nil
+	case "GET location":
+		return map[string]int{"x": e.X, "y": e.Y}, nil
+	case "GET size":
+		return map[string]int{"width": e.Width, "height": e.Height}, nil
+	case "GET rect":
+		return map[string]int{"x": e.X, "y": e.Y, "width": e.Width, "height": e.Height}, nil
+	case "POST click":
+		if e.Hidden || e.Disabled {
+			return nil, errorf(ElementNotInteractable, "element not interactable")
+		}
+		s.click(b, e)
+		return nil, nil
+	case "POST clear":
+		e.Value = ""
+		return nil, nil
+	case "POST value":
+		if e.Hidden || e.Disabled {
+			return nil, errorf(ElementNotInteractable, "element not interactable")
+		}
+		var req struct {
+			Value []string
+			Text  string
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, errorf(InvalidArgument, "invalid value: %v", err)
+		}
+		if req.Text == "" {
+			req.Text = strings.Join(req.Value, "")
+		}
+		e.Value += req.Text
+		return nil, nil
+	case "POST submit":
+		return nil, nil
+	}
+	if name, ok := strings.CutPrefix(pathname, "attribute/"); ok && method == http.MethodGet {
+		if v, ok := e.attribute(name); ok {
+			return v, nil
+		}
+		return nil, nil
+	}
+	if name, ok := strings.CutPrefix(pathname, "css/"); ok && method == http.MethodGet {
+		return e.CSS[name], nil
+	}
+	if id, ok := strings.CutPrefix(pathname, "equals/"); ok && method == http.MethodGet {
+		return s.elements[id] == e, nil
+	}
+	return nil, errorf(UnknownCommand, "unknown command: %s element/%s", method, pathname)
+}
+
+// click toggles the checkbox and the radio button, follows the link and opens the alert.
+func (s *Server) click(b *browser, e *Element) {
+	switch {
+	case e.Tag == "input" && e.Attributes["type"] == "checkbox":
+		e.Selected = !e.Selected
+	case e.Tag == "input" && e.Attributes["type"] == "radio", e.Tag == "option":
+		e.Selected = true
+	}
+	if e.OnClick != nil {
+		e.OnClick()
+	}
+	if href, ok := e.Attributes["href"]; ok && e.Tag == "a" {
+		b.navigate(href)
+	}
+	if e.Alert != "" {
+		alert := e.Alert
+		b.alert = &alert
+	}
+}
@@ -0,0 +1,220 @@
+package webdrivertest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ikawaha/navigator/webdriver/session"
+)
+
+func newTestDocument() *Document {
+	return &Document{
+		Title: "Form",
+		Elements: []*Element{
+			{Tag: "form", Attributes: map[string]string{"id": "login"}, Children: []*Element{
+				{Tag: "input", Attributes: map[string]string{"name": "user", "type": "text", "class": "field large"}},
+				{Tag: "input", Attributes: map[string]string{"id": "remember", "type": "checkbox"}},
+				{Tag: "button", Text: "Sign in", Selectors: []session.Selector{{Using: "xpath", Value: "//button"}}, Alert: "signed in"},
+			}},
+			{Tag: "a", Text: "Next", Attributes: map[string]string{"href": "http://example.com/next"}},
+		},
+	}
+}
+
+func TestServer(t *testing.T) {
+	for _, dialect := range []session.Dialect{session.DialectLegacy, session.DialectW3C} {
+		t.Run(dialect.String(), func(t *testing.T) {
+			srv := NewServer()
+			defer srv.Close()
+			srv.Handle("http://example.com/", newTestDocument())
+			srv.Handle("http://example.com/next", &Document{Title: "Next"})
+			ctx := context.Background()
+
+			s, err := session.OpenWithDialect(ctx, srv.Client(), srv.URL, nil, false, dialect)
+			if err != nil {
+				t.Fatalf("OpenWithDialect() failed: unexpected error %v", err)
+			}
+			if err := s.SetURL(ctx, "http://example.com/"); err != nil {
+				t.Fatalf("SetURL() failed: unexpected error %v", err)
+			}
+			if got, err := s.GetTitle(ctx); err != nil || got != "Form" {
+				t.Errorf("want %q, got %q (%v)", "Form", got, err)
+			}
+
+			input, err := s.GetElement(ctx, session.Selector{Using: "css selector", Value: `form#login input.large[type="text"]`})
+			if err != nil {
+				t.Fatalf("GetElement() failed: unexpected error %v", err)
+			}
+			if err := input.Value(ctx, "alice"); err != nil {
+				t.Fatalf("Value() failed: unexpected error %v", err)
+			}
+			if got, err := input.GetAttribute(ctx, "value"); err != nil || got != "alice" {
+				t.Errorf("want %q, got %q (%v)", "alice", got, err)
+			}
+
+			form, err := s.GetElement(ctx, session.Selector{Using: "id", Value: "login"})
+			if err != nil {
+				t.Fatalf("GetElement() failed: unexpected error %v", err)
+			}
+			inputs, err := form.GetElements(ctx, session.Selector{Using: "tag name", Value: "input"})
+			if err != nil || len(inputs) != 2 {
+				t.Fatalf("want 2 inputs, got %d (%v)", len(inputs), err)
+			}
+			if err := inputs[1].Click(ctx); err != nil {
+				t.Fatalf("Click() failed: unexpected error %v", err)
+			}
+			if selected, err := inputs[1].IsSelected(ctx); err != nil || !selected {
+				t.Errorf("want the checkbox selected, got %v (%v)", selected, err)
+			}
+
+			button, err := s.GetElement(ctx, session.Selector{Using: "xpath", Value: "//button"})
+			if err != nil {
+				t.Fatalf("GetElement() failed: unexpected error %v", err)
+			}
+			if err := button.Click(ctx); err != nil {
+				t.Fatalf("Click() failed: unexpected error %v", err)
+			}
+			if got, err := s.GetAlertText(ctx); err != nil || got != "signed in" {
+				t.Errorf("want %q, got %q (%v)", "signed in", got, err)
+			}
+			if err := s.AcceptAlert(ctx); err != nil {
+				t.Fatalf("AcceptAlert() failed: unexpected error %v", err)
+			}
+
+			link, err := s.GetElement(ctx, session.Selector{Using: "link text", Value: "Next"})
+			if err != nil {
+				t.Fatalf("GetElement() failed: unexpected error %v", err)
+			}
+			if err := link.Click(ctx); err != nil {
+				t.Fatalf("Click() failed: unexpected error %v", err)
+			}
+			if got := srv.SessionURL(s.ID()); got != "http://example.com/next" {
+				t.Errorf("want %q, got %q", "http://example.com/next", got)
+			}
+			// the elements of the previous page are stale.
+			if _, err := input.GetText(ctx); err == nil || !strings.Contains(err.Error(), "not attached") {
+				t.Errorf("want the stale element error, got %v", err)
+			}
+			if _, err := s.GetElement(ctx, session.Selector{Using: "id", Value: "login"}); err == nil {
+				t.Errorf("expected error, but nil")
+			}
+
+			srv.AssertReceived(t, http.MethodPost, "element/*/click")
+			srv.AssertNotReceived(t, http.MethodPost, "element/*/clear")
+			if err := s.Delete(ctx); err != nil {
+				t.Fatalf("Delete() failed: unexpected error %v", err)
+			}
+			if got := srv.Sessions(); len(got) != 0 {
+				t.Errorf("want no sessions, got %v", got)
+			}
+		})
+	}
+}
+
+func TestServer_Inject(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.Handle("http://example.com/", newTestDocument())
+	ctx := context.Background()
+	s, err := session.OpenWithClient(ctx, srv.Client(), srv.URL, nil, false)
+	if err != nil {
+		t.Fatalf("OpenWithClient() failed: unexpected error %v", err)
+	}
+
+	t.Run("error code", func(t *testing.T) {
+		srv.Inject(Fault{Method: http.MethodPost, Path: "url", Error: UnknownError, Message: "net::ERR_NAME_NOT_RESOLVED", Times: 1})
+		if err := s.SetURL(ctx, "http://example.com/"); err == nil || !strings.Contains(err.Error(), "ERR_NAME_NOT_RESOLVED") {
+			t.Errorf("want the injected error, got %v", err)
+		}
+		// the fault is injected once.
+		if err := s.SetURL(ctx, "http://example.com/"); err != nil {
+			t.Errorf("SetURL() failed: unexpected error %v", err)
+		}
+	})
+	t.Run("stale element", func(t *testing.T) {
+		srv.Inject(Fault{Path: "element/*/text", Error: StaleElementReference, Times: 1})
+		e, err := s.GetElement(ctx, session.Selector{Using: "tag name", Value: "a"})
+		if err != nil {
+			t.Fatalf("GetElement() failed: unexpected error %v", err)
+		}
+		if _, err := e.GetText(ctx); err == nil {
+			t.Errorf("expected error, but nil")
+		}
+		if got, err := e.GetText(ctx); err != nil || got != "Next" {
+			t.Errorf("want %q, got %q (%v)", "Next", got, err)
+		}
+	})
+	t.Run("timeout", func(t *testing.T) {
+		srv.Inject(Fault{Path: "title", Delay: time.Second})
+		defer srv.ClearFaults()
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if _, err := s.GetTitle(ctx); err == nil {
+			t.Errorf("expected error, but nil")
+		}
+	})
+	if got, want := len(srv.Received(http.MethodPost, "url")), 2; got != want {
+		t.Errorf("want %d commands, got %d", want, got)
+	}
+}
+
+func TestServer_Session(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.HandleLog("browser", session.Log{Message: "console.log (app.js:1:2)", Level: "INFO", Timestamp: 1})
+	ctx := context.Background()
+
+	s, err := session.OpenWithClient(ctx, srv.Client(), srv.URL, map[string]any{"browserName": "chrome"}, false)
+	if err != nil {
+		t.Fatalf("OpenWithClient() failed: unexpected error %v", err)
+	}
+
+	t.Run("logs", func(t *testing.T) {
+		logs, err := s.NewLogs(ctx, "browser")
+		if err != nil {
+			t.Fatalf("NewLogs() failed: unexpected error %v", err)
+		}
+		if len(logs) != 1 || logs[0].Message != "console.log (app.js:1:2)" {
+			t.Errorf("want the handled log, got %+v", logs)
+		}
+	})
+	t.Run("cdp", func(t *testing.T) {
+		if err := s.ExecuteCDP(ctx, "Emulation.setTimezoneOverride", map[string]any{"timezoneId": "Asia/Tokyo"}, nil); err != nil {
+			t.Fatalf("ExecuteCDP() failed: unexpected error %v", err)
+		}
+		got := srv.CDPCommands()
+		if len(got) != 1 || got[0].Cmd != "Emulation.setTimezoneOverride" || got[0].Params["timezoneId"] != "Asia/Tokyo" || got[0].SessionID != s.ID() {
+			t.Errorf("want the CDP command, got %+v", got)
+		}
+	})
+	t.Run("overlapped", func(t *testing.T) {
+		srv.Inject(Fault{Path: "title", Delay: 50 * time.Millisecond, Times: 1})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = s.GetTitle(ctx)
+		}()
+		for len(srv.Received(http.MethodGet, "title")) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		// a raw request bypasses the serialization of the session.
+		resp, err := srv.Client().Get(srv.URL + "/session/" + s.ID() + "/url")
+		if err != nil {
+			t.Fatalf("Get() failed: unexpected error %v", err)
+		}
+		resp.Body.Close()
+		<-done
+		if got := srv.Overlapped(); len(got) != 1 || got[0].Path != "url" {
+			t.Errorf("want the url command overlapped, got %+v", got)
+		}
+	})
+	t.Run("lose", func(t *testing.T) {
+		srv.Lose(s.ID())
+		if _, err := s.GetTitle(ctx); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("want the session not found, got %v", err)
+		}
+	})
+}
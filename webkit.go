@@ -0,0 +1,63 @@
+package navigator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// WebKitConfig is the configuration of a WebKitGTK browser passed to
+// WebKitWebDriver under the webkitgtk:browserOptions capability.
+//
+// See: https://webkitgtk.org/reference/webkit2gtk/stable/class.WebView.html#webdriver
+type WebKitConfig struct {
+	// Binary is the path to the browser executable (ex. "/usr/bin/MiniBrowser").
+	Binary string
+	// Args are the command-line arguments of the browser (ex. "--automation").
+	Args []string
+	// Certificates are the additional certificates trusted for the hosts.
+	Certificates []WebKitCertificate
+}
+
+// WebKitCertificate is a certificate file trusted for the host.
+type WebKitCertificate struct {
+	// Host is the host name the certificate is trusted for.
+	Host string `json:"host"`
+	// CertificateFile is the path to the PEM encoded certificate.
+	CertificateFile string `json:"certificateFile"`
+}
+
+func (c *WebKitConfig) validate() error {
+	if c.Binary != "" {
+		if _, err := os.Stat(c.Binary); err != nil {
+			return fmt.Errorf("invalid binary: %w", err)
+		}
+	}
+	for _, v := range c.Certificates {
+		if v.Host == "" {
+			return errors.New("invalid certificate: host is required")
+		}
+		if _, err := os.Stat(v.CertificateFile); err != nil {
+			return fmt.Errorf("invalid certificate: %w", err)
+		}
+	}
+	return nil
+}
+
+// options returns the configuration as the value of webkitgtk:browserOptions.
+func (c *WebKitConfig) options() (map[string]any, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	opts := map[string]any{}
+	if c.Binary != "" {
+		opts["binary"] = c.Binary
+	}
+	if len(c.Args) > 0 {
+		opts["args"] = c.Args
+	}
+	if len(c.Certificates) > 0 {
+		opts["certificates"] = c.Certificates
+	}
+	return opts, nil
+}